@@ -1,6 +1,10 @@
 package colorplus
 
-import "math"
+import (
+    "context"
+    "fmt"
+    "math"
+)
 
 // 3D Lookup table, designed for interop with the 3DL2 and 3DLUT specifications
 // TODO: Redesign this from scratch to conform better to the Go style
@@ -64,6 +68,9 @@ type LUT3D struct {
 
     //   The lutUncompressedSize of the array is calculated as:
     //     lutDim = 3 × 2∧inputBitDepth[0] × 2∧inputBitDepth[1] × 2∧inputBitDepth[2] × outputBitDepth÷8
+
+    Interpolation InterpolationMode // Used when applying the 3DLUT as a filter, not part of the file format
+    Rounding RoundingMode           // Used when storing integer outputs, not part of the file format
 }
 
 // Primaries information. This is redundant with the existing ColorSpace structure though, consider removing this in future revisions
@@ -100,7 +107,7 @@ func (b lutByte) Get(index int) float64 {
 }
 
 func (b lutByte) Set(index int, value float64) {
-    b[index] = byte(math.Floor(value))
+    b[index] = byte(math.Max(0, math.Min(0xFF, math.Floor(value))))
 }
 
 // Short implementation
@@ -109,7 +116,7 @@ func (s lutShort) Get(index int) float64 {
 }
 
 func (s lutShort) Set(index int, value float64) {
-    s[index] = uint16(math.Floor(value))
+    s[index] = uint16(math.Max(0, math.Min(0xFFFF, math.Floor(value))))
 }
 
 // Float implementation
@@ -132,8 +139,21 @@ func (d lutDouble) Set(index int, value float64) {
 
 // 3DLUT creation
 func Make3DLUT(InputDepth []int32, OutputDepth int32, InputRange, OutputRange, InputEncoding, OutputEncoding int32, InputSpace, OutputSpace Space) *LUT3D {
+    lut, err := Make3DLUTChecked(InputDepth, OutputDepth, InputRange, OutputRange, InputEncoding, OutputEncoding, InputSpace, OutputSpace)
+    if err != nil {
+        panic(err)
+    }
+
+    return lut
+}
+
+func Make3DLUTChecked(InputDepth []int32, OutputDepth int32, InputRange, OutputRange, InputEncoding, OutputEncoding int32, InputSpace, OutputSpace Space) (*LUT3D, error) {
     lut := LUT3D{}
 
+    if (len(InputDepth) != 3) {
+        return nil, ErrInvalidBitDepth
+    }
+
     lut.Signature = [4]byte{'3', 'D', 'L', '2'}
     lut.FileVersion = 2
     lut.ProgramName = [32]byte{'C', 'o', 'l', 'o', 'r', 'P', 'l', 'u', 's'}
@@ -158,63 +178,151 @@ func Make3DLUT(InputDepth []int32, OutputDepth int32, InputRange, OutputRange, I
     lut.InputValueRange = InputRange
     lut.OutputValueRange = OutputRange
 
+    if err := lut.validate(); err != nil {
+        return nil, err
+    }
+
     lut.LutData = makeLutData(OutputDepth, lut.LutUncompressedSize)
 
-    return &lut
+    return &lut, nil
+}
+
+// Options for assignment. Workers defaults to GOMAXPROCS if zero, Progress is optional and receives the number of
+// lattice points done so far. Calls to Progress are serialized, but may come from any goroutine
+type AssignOptions struct {
+    Workers int
+    Progress func(done, total int)
 }
 
 // Assignment logic
 func (lut *LUT3D) Assign(filter FilterTripleProvider, pipeline bool) {
-    var f FilterTriple
+    if err := lut.AssignContext(context.Background(), filter, pipeline, AssignOptions{}); err != nil {
+        panic(err)
+    }
+}
 
+// Assignment with explicit options, stops early and returns ctx.Err() if ctx is cancelled. The filter is built once
+// and shared between all workers, so it must be safe for concurrent use (all built-in filters are). Unsupported types
+// are reported before any work is done, and a panic in the filter stops the assignment and is returned as an error
+func (lut *LUT3D) AssignContext(ctx context.Context, filter FilterTripleProvider, pipeline bool, opts AssignOptions) error {
     if pipeline {
-        f = Chain(Multiplex(
-                Pulldown{uint(lut.InputBitDepth[0]), lut.InputValueRange == RangeFull},
-                Pulldown{uint(lut.InputBitDepth[1]), lut.InputValueRange == RangeFull},
-                Pulldown{uint(lut.InputBitDepth[2]), lut.InputValueRange == RangeFull}),
-                filter,
-                Pullup{uint(lut.OutputBitDepth), lut.OutputValueRange == RangeFull}).GetTriple()
-    } else {
-        f = filter.GetTriple()
+        filter = Chain(lut.inputRange(false), filter, lut.outputRange(true))
+    }
+
+    f, out, err := getTripleChecked(filter, lut.latticePoint(0, 0, 0))
+    if err != nil {
+        return err
+    }
+
+    if err := lut.checkOutput(out); err != nil {
+        return err
     }
 
-    // Assign, TODO: multi-thread
+    // The lattice is split into planes of constant C, which are handed out to the workers in order
     maxa, maxb, maxc := 1 << uint(lut.InputBitDepth[0]), 1 << uint(lut.InputBitDepth[1]), 1 << uint(lut.InputBitDepth[2])
-    pos := 0
+    total, done := maxa * maxb * maxc, 0
 
-    for c := 0; c < maxc; c++ {
+    plane := func(c int) error {
+        pos := lut.Offset(0, 0, c)
         for b := 0; b < maxb; b++ {
             for a := 0; a < maxa; a++ {
-                lut.SetOutputRaw(pos, f(RGB{float64(c), float64(b), float64(a)}))
+                lut.SetOutputRaw(pos, f(lut.latticePoint(float64(a), float64(b), float64(c))))
                 pos += 3
             }
         }
+        return nil
+    }
+
+    progress := func(int) {
+        if (opts.Progress != nil) {
+            done += maxa * maxb
+            opts.Progress(done, total)
+        }
+    }
+
+    return parallel(ctx, opts.Workers, maxc, plane, progress)
+}
+
+// Filter provider implementation. Inputs are normalized values in the input encoding and range, which are mapped
+// onto the lattice and interpolated. Outputs are normalized from the output bit depth and range
+func (lut *LUT3D) GetTriple() FilterTriple {
+    n := [3]int{1 << uint(lut.InputBitDepth[0]) - 1, 1 << uint(lut.InputBitDepth[1]) - 1, 1 << uint(lut.InputBitDepth[2]) - 1}
+    out := lut.GetOutputRaw(0)
+    pullup, pulldown := lut.inputRange(true).GetTriple(), lut.outputRange(false).GetTriple()
+
+    get := func(a, b, c int) (float64, float64, float64) {
+        return lut.GetOutputRaw(lut.Offset(a, b, c)).Get()
+    }
+
+    return func(in Triple) Triple {
+        a, b, c := lut.latticeIndex(pullup(in))
+        pos := [3]float64{a, b, c}
+
+        for i := range pos {
+            pos[i] = math.Max(0, math.Min(float64(n[i]), pos[i]))
+        }
+
+        return pulldown(out.Make(interpolate3D(lut.Interpolation, get, n, pos)))
+    }
+}
+
+func (lut *LUT3D) InputType() Triple {
+    return lut.latticePoint(0, 0, 0)
+}
+
+func (lut *LUT3D) GetTripleChecked(in Triple) (FilterTriple, Triple, error) {
+    if err := accepts("LUT3D", in, lut.InputType()); err != nil {
+        return nil, nil, err
     }
+
+    return lut.GetTriple(), lut.GetOutputRaw(0), nil
+}
+
+// Precaching, bakes a filter into a full range floating point 3DLUT with 2^depth points per axis. Inputs outside
+// the range 0-1 are clamped when applying the result
+func Precache(filter FilterTripleProvider, depth int32, mode InterpolationMode) *LUT3D {
+    lut := Make3DLUT([]int32{depth, depth, depth}, 64, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpaceNone, SpaceNone)
+    lut.InputColorSpace, lut.OutputColorSpace = PRIMARIES{}, PRIMARIES{} // unknown
+    lut.Interpolation = mode
+    lut.Assign(filter, true)
+
+    return lut
 }
 
 // Functions for internal logic
 func (lut *LUT3D) SetOutputRaw(pos int, output Triple) {
     switch lut.OutputColorEncoding {
     case 0: // BGR
-        rgb := output.(RGB)
-        lut.LutData.Set(pos, rgb.B)
-        lut.LutData.Set(pos + 1, rgb.G)
-        lut.LutData.Set(pos + 2, rgb.R)
+        rgb, ok := output.(RGB)
+        if (!ok) {
+            panic(unsupported("SetOutputRaw", output))
+        }
+
+        lut.set(pos, rgb.B)
+        lut.set(pos + 1, rgb.G)
+        lut.set(pos + 2, rgb.R)
 
     case 1: // YCbCr
-        panic("[SetOutputRaw] YCbCr currently unsupported!")
+        ycc, ok := output.(YCbCr)
+        if (!ok) {
+            panic(unsupported("SetOutputRaw", output))
+        }
+
+        lut.set(pos, ycc.Y)
+        lut.set(pos + 1, ycc.Cb)
+        lut.set(pos + 2, ycc.Cr)
 
     case 2: // XYZ
         var xyz XYZ
         switch x := output.(type) {
             case XYZ: xyz = x
             case Yxy: xyz = x.ToXYZ()
-            default: panic("[SetOutputRaw] Unsupported color type for XYZ output")
+            default: panic(unsupported("SetOutputRaw", output))
         }
 
-        lut.LutData.Set(pos, xyz.X)
-        lut.LutData.Set(pos + 1, xyz.Y)
-        lut.LutData.Set(pos + 2, xyz.Z)
+        lut.set(pos, xyz.X)
+        lut.set(pos + 1, xyz.Y)
+        lut.set(pos + 2, xyz.Z)
 
     default: panic("[SetOutputRaw] Unsupported output encoding")
     }
@@ -225,13 +333,100 @@ func (lut *LUT3D) GetOutputRaw(pos int) Triple {
 
     switch lut.OutputColorEncoding {
         case 0: return RGB{c, b, a} // BGR
-        case 1: panic("[GetOutputRaw] YCbCr currently unsupported!") // YCbCr
+        case 1: return YCbCr{a, b, c} // YCbCr
         case 2: return XYZ{a, b, c} // XYZ
     }
 
     return nil
 }
 
+// Bit depth for pullup/pulldown of output values, floating point outputs are normalized
+func (lut *LUT3D) outputDepth() uint {
+    if (lut.OutputBitDepth > 16) {
+        return 0
+    }
+    return uint(lut.OutputBitDepth)
+}
+
+// Make sure the output of a filter can be stored
+func (lut *LUT3D) checkOutput(out Triple) error {
+    ok := false
+
+    switch out.(type) {
+        case nil: ok = true // unknown, SetOutputRaw checks each value
+        case RGB: ok = lut.OutputColorEncoding == EncodingBGR
+        case YCbCr: ok = lut.OutputColorEncoding == EncodingYCbCr
+        case XYZ, Yxy: ok = lut.OutputColorEncoding == EncodingXYZ
+    }
+
+    if !ok {
+        return fmt.Errorf("%w for output encoding %d", unsupported("LUT3D", out), lut.OutputColorEncoding)
+    }
+    return nil
+}
+
+// The lattice point with the given indices, as integer codes in the input encoding
+func (lut *LUT3D) latticePoint(A, B, C float64) Triple {
+    switch lut.InputColorEncoding {
+        case EncodingBGR: return RGB{C, B, A}
+        case EncodingYCbCr: return YCbCr{A, B, C}
+        default: panic("[LUT3D] Unsupported input encoding")
+    }
+}
+
+// The (fractional) lattice indices of a point in the input encoding, the inverse of latticePoint
+func (lut *LUT3D) latticeIndex(in Triple) (A, B, C float64) {
+    x, y, z := in.Get()
+
+    if (lut.InputColorEncoding == EncodingBGR) {
+        return z, y, x
+    }
+    return x, y, z
+}
+
+// Conversion between normalized values and integer codes, for the input and output encoding respectively
+func (lut *LUT3D) inputRange(up bool) FilterTripleProvider {
+    d := [3]uint{uint(lut.InputBitDepth[0]), uint(lut.InputBitDepth[1]), uint(lut.InputBitDepth[2])}
+
+    if (lut.InputColorEncoding == EncodingBGR) { // bit depths are in lattice order, but the filter is in RGB order
+        d[0], d[2] = d[2], d[0]
+    }
+
+    return rangeFilter(lut.InputColorEncoding, d, lut.InputValueRange == RangeFull, up)
+}
+
+func (lut *LUT3D) outputRange(up bool) FilterTripleProvider {
+    d := lut.outputDepth()
+
+    return rangeFilter(lut.OutputColorEncoding, [3]uint{d, d, d}, lut.OutputValueRange == RangeFull, up)
+}
+
+// Per-channel pullup (or pulldown) for the given encoding, chroma channels use their own limits
+func rangeFilter(encoding int32, depth [3]uint, full, up bool) FilterTripleProvider {
+    var f [3]FilterSingleProvider
+
+    for i, d := range depth {
+        chroma := encoding == EncodingYCbCr && i > 0
+
+        switch {
+            case chroma && up: f[i] = ChromaPullup{d, full}
+            case chroma: f[i] = ChromaPulldown{d, full}
+            case up: f[i] = Pullup{d, full}
+            default: f[i] = Pulldown{d, full}
+        }
+    }
+
+    return Multiplex(f[0], f[1], f[2])
+}
+
+// Store a single output value, rounding it first for integer depths
+func (lut *LUT3D) set(pos int, value float64) {
+    if (lut.OutputBitDepth <= 16) {
+        value = lut.Rounding.round(value)
+    }
+    lut.LutData.Set(pos, value)
+}
+
 func (lut *LUT3D) Offset(A, B, C int) int {
     return 3 * ((C << uint(lut.InputBitDepth[1] + lut.InputBitDepth[0])) + (B << uint(lut.InputBitDepth[0])) + A)
 }
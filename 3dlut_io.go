@@ -0,0 +1,183 @@
+package colorplus
+
+import (
+    "encoding/binary"
+    "errors"
+    "io"
+)
+
+// Serialization of LUT3D to and from the 3DL2 file format. All values are stored in little-endian byte order
+
+var (
+    ErrInvalidSignature = errors.New("[LUT3D] Invalid file signature")
+    ErrUnsupportedVersion = errors.New("[LUT3D] Unsupported file version")
+    ErrUnsupportedCompression = errors.New("[LUT3D] Unsupported compression method")
+    ErrInvalidBitDepth = errors.New("[LUT3D] Invalid bit depth")
+    ErrInvalidSize = errors.New("[LUT3D] Size does not match the bit depths")
+    ErrInvalidOffset = errors.New("[LUT3D] Invalid or misaligned data offset")
+    ErrInvalidEncoding = errors.New("[LUT3D] Invalid color encoding or value range")
+)
+
+const (
+    lutHeaderSize = 232     // size in bytes of all fixed-size fields, up to and including OutputColorSpace
+    lutAlignment = 16384    // required alignment of LutFileOffset
+)
+
+// Write the 3DLUT to w, implements io.WriterTo
+func (lut *LUT3D) WriteTo(w io.Writer) (int64, error) {
+    if err := lut.validate(); err != nil {
+        return 0, err
+    }
+
+    if (int(lut.ParametersSize) != len(lut.ParametersData)) {
+        return 0, ErrInvalidSize
+    }
+
+    if (lut.LutFileOffset % lutAlignment != 0) {
+        return 0, ErrInvalidOffset
+    }
+
+    cw := &countingWriter{w: w}
+
+    for _, v := range lut.header() {
+        if err := binary.Write(cw, binary.LittleEndian, v); err != nil {
+            return cw.n, err
+        }
+    }
+
+    if err := cw.pad(int64(lut.ParametersFileOffset)); err != nil {
+        return cw.n, err
+    }
+
+    if _, err := cw.Write(lut.ParametersData); err != nil {
+        return cw.n, err
+    }
+
+    if err := cw.pad(int64(lut.LutFileOffset)); err != nil {
+        return cw.n, err
+    }
+
+    err := binary.Write(cw, binary.LittleEndian, lut.LutData)
+    return cw.n, err
+}
+
+// Read a 3DLUT from r. Since r need not be seekable, the parameters must precede the LUT data in the file
+func Read3DLUT(r io.Reader) (*LUT3D, error) {
+    lut := LUT3D{}
+    pos := int64(0)
+
+    for _, v := range lut.header() {
+        if err := binary.Read(r, binary.LittleEndian, v); err != nil {
+            return nil, err
+        }
+        pos += int64(binary.Size(v))
+    }
+
+    if err := lut.validate(); err != nil {
+        return nil, err
+    }
+
+    if err := skip(r, int64(lut.ParametersFileOffset) - pos); err != nil {
+        return nil, err
+    }
+
+    lut.ParametersData = make([]byte, lut.ParametersSize)
+    if _, err := io.ReadFull(r, lut.ParametersData); err != nil {
+        return nil, err
+    }
+
+    if err := skip(r, int64(lut.LutFileOffset - lut.ParametersFileOffset - lut.ParametersSize)); err != nil {
+        return nil, err
+    }
+
+    lut.LutData = makeLutData(lut.OutputBitDepth, lut.LutUncompressedSize)
+    if err := binary.Read(r, binary.LittleEndian, lut.LutData); err != nil {
+        return nil, err
+    }
+
+    return &lut, nil
+}
+
+// Pointers to all fixed-size header fields, in file order
+func (lut *LUT3D) header() []interface{} {
+    return []interface{}{
+        &lut.Signature, &lut.FileVersion, &lut.ProgramName, &lut.ProgramVersion,
+        &lut.InputBitDepth, &lut.InputColorEncoding, &lut.InputValueRange,
+        &lut.OutputBitDepth, &lut.OutputColorEncoding, &lut.OutputValueRange,
+        &lut.ParametersFileOffset, &lut.ParametersSize, &lut.LutFileOffset,
+        &lut.LutCompressionMethod, &lut.LutCompressedSize, &lut.LutUncompressedSize,
+        &lut.InputColorSpace, &lut.OutputColorSpace}
+}
+
+// Consistency checks shared by reading and writing
+func (lut *LUT3D) validate() error {
+    if (lut.Signature != [4]byte{'3', 'D', 'L', '2'}) {
+        return ErrInvalidSignature
+    }
+
+    if (lut.FileVersion != 2) {
+        return ErrUnsupportedVersion
+    }
+
+    if (lut.LutCompressionMethod != 0) {
+        return ErrUnsupportedCompression
+    }
+
+    if (lut.InputColorEncoding < EncodingBGR || lut.InputColorEncoding > EncodingYCbCr ||
+        lut.OutputColorEncoding < EncodingBGR || lut.OutputColorEncoding > EncodingXYZ ||
+        lut.InputValueRange < RangeFull || lut.InputValueRange > RangeLimited ||
+        lut.OutputValueRange < RangeFull || lut.OutputValueRange > RangeLimited) {
+        return ErrInvalidEncoding
+    }
+
+    size := int64(3)
+    for _, d := range lut.InputBitDepth {
+        if (d < 1 || d > 16) {
+            return ErrInvalidBitDepth
+        }
+        size <<= uint(d)
+    }
+
+    switch lut.OutputBitDepth {
+        case 8, 16, 32, 64: size *= int64(lut.OutputBitDepth / 8)
+        default: return ErrInvalidBitDepth
+    }
+
+    if (size != int64(lut.LutUncompressedSize) || size != int64(lut.LutCompressedSize)) {
+        return ErrInvalidSize
+    }
+
+    if (lut.ParametersSize < 0 || lut.ParametersFileOffset < lutHeaderSize ||
+        lut.LutFileOffset < lut.ParametersFileOffset + lut.ParametersSize) {
+        return ErrInvalidOffset
+    }
+
+    return nil
+}
+
+// Helpers for tracking the position in the stream
+type countingWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+    n, err := cw.w.Write(p)
+    cw.n += int64(n)
+    return n, err
+}
+
+// Zero-fill up to the given offset
+func (cw *countingWriter) pad(offset int64) error {
+    _, err := cw.Write(make([]byte, offset - cw.n))
+    return err
+}
+
+func skip(r io.Reader, n int64) error {
+    if (n < 0) {
+        return ErrInvalidOffset
+    }
+
+    _, err := io.CopyN(io.Discard, r, n)
+    return err
+}
@@ -1,6 +1,12 @@
 package colorplus
 
-import "testing"
+import (
+    "bytes"
+    "context"
+    "errors"
+    "reflect"
+    "testing"
+)
 
 func TestLut(t *testing.T) {
     lut := Make3DLUT([]int32{4, 4, 4}, 8, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpacesRGB)
@@ -8,5 +14,169 @@ func TestLut(t *testing.T) {
     lut.Assign(Chain(Grayscale, Invert), true)
     rgb := lut.GetOutputRaw(lut.Offset(0, 0, 15)).(RGB)
 
-    FuzzyAssertTriple(RGB{15, 0, 0}, rgb, RGB{200, 200, 200}, allow, "lut.Assign(Chain(Grayscale, Invert), true)", t)
+    FuzzyAssertTriple(RGB{15, 0, 0}, rgb, RGB{201, 201, 201}, allow, "lut.Assign(Chain(Grayscale, Invert), true)", t)
+
+    lut.Rounding = RoundTruncate
+    lut.Assign(Chain(Grayscale, Invert), true)
+    rgb = lut.GetOutputRaw(lut.Offset(0, 0, 15)).(RGB)
+
+    FuzzyAssertTriple(RGB{15, 0, 0}, rgb, RGB{200, 200, 200}, allow, "lut.Assign(Chain(Grayscale, Invert), true) with RoundTruncate", t)
+
+    // Exact halves
+    halves := RGB{2.5, 3.5, 254.5}
+    for _, c := range []struct {
+        mode RoundingMode
+        want RGB
+    }{
+        {RoundNearest, RGB{3, 4, 255}},
+        {RoundHalfEven, RGB{2, 4, 254}},
+        {RoundTruncate, RGB{2, 3, 254}},
+    } {
+        lut.Rounding = c.mode
+        lut.SetOutputRaw(0, halves)
+        FuzzyAssertTriple(halves, lut.GetOutputRaw(0), c.want, allow, "lut.SetOutputRaw", t)
+    }
+}
+
+func TestLutIO(t *testing.T) {
+    for _, depth := range []int32{8, 16, 32, 64} {
+        lut := Make3DLUT([]int32{3, 4, 5}, depth, RangeFull, RangeLimited, EncodingBGR, EncodingBGR, SpacesRGB, SpaceAdobeRGB98)
+        lut.ParametersData = []byte("Input_Transfer_Function sRGB")
+        lut.ParametersSize = int32(len(lut.ParametersData))
+        lut.Assign(Chain(SpacesRGB.GetDecoder(), SpaceAdobeRGB98.GetEncoder()), true)
+
+        var buf bytes.Buffer
+        n, err := lut.WriteTo(&buf)
+
+        if err != nil || n != int64(buf.Len()) || n != int64(lut.LutFileOffset + lut.LutUncompressedSize) {
+            t.Fatalf("lut.WriteTo() = %v, %v for depth %v", n, err, depth)
+        }
+
+        res, err := Read3DLUT(&buf)
+        if err != nil {
+            t.Fatalf("Read3DLUT() = %v for depth %v", err, depth)
+        }
+
+        if !reflect.DeepEqual(lut, res) {
+            t.Errorf("Read3DLUT(lut.WriteTo()) differs from lut for depth %v", depth)
+        }
+    }
+
+    // Corrupted files
+    lut := Make3DLUT([]int32{2, 2, 2}, 8, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpacesRGB)
+    var buf bytes.Buffer
+    lut.WriteTo(&buf)
+
+    data := buf.Bytes()
+    data[0] = 'X'
+
+    if _, err := Read3DLUT(bytes.NewReader(data)); err != ErrInvalidSignature {
+        t.Errorf("Read3DLUT(bad signature) = %v, want %v", err, ErrInvalidSignature)
+    }
+
+    lut.LutUncompressedSize++
+    if _, err := lut.WriteTo(&buf); err != ErrInvalidSize {
+        t.Errorf("lut.WriteTo(bad size) = %v, want %v", err, ErrInvalidSize)
+    }
+}
+
+func TestLutInterpolation(t *testing.T) {
+    chain := Chain(SpacesRGB.GetDecoder(), XYZSpace(SpaceAdobeRGB98).GetEncoder(), SRGBCurve.GetEncoder())
+    f := chain.GetTriple()
+
+    for _, mode := range []InterpolationMode{Trilinear, Tetrahedral} {
+        lut := Precache(chain, 6, mode)
+        g := lut.GetTriple()
+
+        // Lattice points must be exact, everything else close
+        FuzzyAssertTriple(RGB{1, 0, 0}, g(RGB{1, 0, 0}), f(RGB{1, 0, 0}), allow, "Precache(chain).GetTriple()", t)
+        FuzzyAssertTriple(RGB{0.3, 0.6, 0.9}, g(RGB{0.3, 0.6, 0.9}), f(RGB{0.3, 0.6, 0.9}), 0.001, "Precache(chain).GetTriple()", t)
+    }
+
+    // Tetrahedral interpolation is exact on the neutral axis of an identity LUT, even with limited range and integer output
+    lut := Make3DLUT([]int32{4, 4, 4}, 16, RangeLimited, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpacesRGB)
+    lut.Interpolation = Tetrahedral
+    lut.Assign(Chain(Identity), true)
+
+    FuzzyAssertTriple(RGB{0.4, 0.4, 0.4}, lut.GetTriple()(RGB{0.4, 0.4, 0.4}), RGB{0.4, 0.4, 0.4}, 0.0001, "lut.GetTriple()", t)
+    FuzzyAssertTriple(RGB{0.1, 0.5, 0.7}, lut.GetTriple()(RGB{0.1, 0.5, 0.7}), RGB{0.1, 0.5, 0.7}, 0.0001, "lut.GetTriple()", t)
+
+    // Both modes reproduce an affine lattice exactly, for every ordering of the fractions, without allocating
+    get := func(a, b, c int) (float64, float64, float64) {
+        return float64(a + 2 * b + 4 * c), float64(3 * a - c), float64(b)
+    }
+
+    for _, mode := range []InterpolationMode{Trilinear, Tetrahedral} {
+        for _, pos := range [][3]float64{{0.7, 0.5, 0.2}, {0.7, 0.2, 0.5}, {0.5, 0.2, 0.7}, {0.5, 0.7, 0.2}, {0.2, 0.7, 0.5}, {0.2, 0.5, 0.7}, {0.5, 0.5, 0.5}, {1.5, 1.5, 0.25}} {
+            a, b, c := interpolate3D(mode, get, [3]int{2, 2, 2}, pos)
+            FuzzyAssertTriple(pos, RGB{a, b, c}, RGB{pos[0] + 2 * pos[1] + 4 * pos[2], 3 * pos[0] - pos[2], pos[1]}, allow, "interpolate3D", t)
+        }
+
+        if n := testing.AllocsPerRun(100, func() { interpolate3D(mode, get, [3]int{2, 2, 2}, [3]float64{0.2, 0.7, 0.5}) }); (n != 0) {
+            t.Errorf("interpolate3D(%v) allocates %v times", mode, n)
+        }
+    }
+}
+
+func TestLutParallel(t *testing.T) {
+    chain := Chain(SpacesRGB.GetDecoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}, SpaceProPhotoRGB.GetEncoder(), Clamp{0, 1})
+
+    serial := Make3DLUT([]int32{5, 5, 5}, 16, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpaceProPhotoRGB)
+    serial.AssignContext(context.Background(), chain, true, AssignOptions{Workers: 1})
+
+    last := 0
+    parallel := Make3DLUT([]int32{5, 5, 5}, 16, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpaceProPhotoRGB)
+    err := parallel.AssignContext(context.Background(), chain, true, AssignOptions{Workers: 7, Progress: func(done, total int) {
+        if (done <= last || done > total) {
+            t.Errorf("Progress(%v, %v) after %v", done, total, last)
+        }
+        last = done
+    }})
+
+    if (err != nil || last != 1 << 15) {
+        t.Errorf("AssignContext() = %v, progress %v", err, last)
+    }
+
+    if !reflect.DeepEqual(serial.LutData, parallel.LutData) {
+        t.Errorf("parallel and serial assignment differ")
+    }
+
+    // Cancellation
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    if err := parallel.AssignContext(ctx, chain, true, AssignOptions{}); err != context.Canceled {
+        t.Errorf("AssignContext(cancelled) = %v, want %v", err, context.Canceled)
+    }
+}
+
+func TestLutYCbCr(t *testing.T) {
+    // YCbCr input, such as for a video renderer
+    lut := Make3DLUT([]int32{6, 6, 6}, 16, RangeLimited, RangeFull, EncodingYCbCr, EncodingBGR, SpacesRGB, SpacesRGB)
+    lut.Assign(YCbCrBT709.GetDecoder(), true)
+
+    in := YCbCr{0.5, 0.1, -0.1}
+    FuzzyAssertTriple(in, lut.GetTriple()(in), YCbCrBT709.GetDecoder()(in), 0.0001, "lut.GetTriple()", t)
+
+    // Black is at code 16, neutral chroma at code 128
+    black := lut.GetOutputRaw(lut.Offset(16 >> 2, 128 >> 2, 128 >> 2))
+    FuzzyAssertTriple("", black, RGB{0, 0, 0}, 1, "lut.GetOutputRaw()", t)
+
+    // YCbCr output
+    lut = Make3DLUT([]int32{4, 4, 4}, 32, RangeFull, RangeLimited, EncodingBGR, EncodingYCbCr, SpacesRGB, SpacesRGB)
+    lut.Assign(YCbCrBT2020.GetEncoder(), true)
+
+    res := lut.GetOutputRaw(lut.Offset(15, 15, 15))
+    FuzzyAssertTriple("", res, YCbCr{235.0 / 255, 128.0 / 255, 128.0 / 255}, allow, "lut.GetOutputRaw()", t)
+    FuzzyAssertTriple(RGB{1, 1, 1}, lut.GetTriple()(RGB{1, 1, 1}), YCbCr{1, 0, 0}, allow, "lut.GetTriple()", t)
+
+    // Storing the wrong type is reported like in all other filters
+    func() {
+        defer func() {
+            if err, _ := recover().(error); !errors.Is(err, ErrUnsupportedType) {
+                t.Errorf("lut.SetOutputRaw(RGB) panicked with %v, want %v", err, ErrUnsupportedType)
+            }
+        }()
+        lut.SetOutputRaw(0, RGB{})
+    }()
 }
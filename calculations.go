@@ -1,31 +1,52 @@
 package colorplus
 
+import (
+    "errors"
+    "fmt"
+    "math"
+)
+
 // Determine the luminance (brightness) of a color triple
 func Luminance(in Triple) float64 {
+    l, err := LuminanceChecked(in)
+    if err != nil {
+        panic(err)
+    }
+
+    return l
+}
+
+func LuminanceChecked(in Triple) (float64, error) {
+    var l float64
+
     switch x := in.(type) {
-        case XYZ: return x.Y
-        case Yxy: return x.Y
-        case RGB: return 0.2126 * x.R + 0.0722 * x.B + 0.7152 * x.G
-        default: panic("[Luminance] Unsupported color type!")
+        case XYZ: l = x.Y
+        case Yxy: l = x.Y
+        case Yuv: l = x.Y
+        case YCbCr: l = x.Y // luma, not luminance
+        case RGB: l = 0.2126 * x.R + 0.0722 * x.B + 0.7152 * x.G
+        case Lab: l = LStarIntent.labFInverse((x.L + 0.16) / 1.16) // relative to the reference white
+        case LCh: l = LStarIntent.labFInverse((x.L + 0.16) / 1.16)
+        case Luv: l = LStarIntent.labFInverse((x.L + 0.16) / 1.16)
+        case LChuv: l = LStarIntent.labFInverse((x.L + 0.16) / 1.16)
+        default: return 0, unsupported("Luminance", in)
     }
-    return 0
+
+    return l, nil
 }
 
-// Calculate a white point from a color temperature
+// Calculate a white point from a color temperature, on the daylight locus (see temperature.go for other options)
 func FromTemperature(T float64) XYZ {
-    var x float64
-
-    if (4000 <= T && T <= 7000) {
-        x = -4.6070E9 / (T*T*T) + 2.9678E6 / (T*T) + 9.911E1 / T + 0.244063
-    } else if (7000 < T && T <= 25000) {
-        x = -2.0064E9 / (T*T*T) + 1.9018E6 / (T*T) + 2.4748E2 / T + 0.237040
-    } else {
-        panic("[FromTemperature] Color temperature out of range!")
+    w, err := FromTemperatureChecked(T)
+    if err != nil {
+        panic(err)
     }
 
-    y := -3 * x * x + 2.87 * x - 0.275
+    return w
+}
 
-    return Yxy{1, x, y}.ToXYZ()
+func FromTemperatureChecked(T float64) (XYZ, error) {
+    return DaylightLocus(T)
 }
 
 // Pullup/pulldown
@@ -51,126 +72,280 @@ func calcLimits(depth uint, full bool) (bot, lim float64) {
     return bot, float64 ((uint(235) << (depth - 8))) - bot
 }
 
-// Chromatic adaptation
+// Chroma pullup/pulldown, mid is the code for zero chroma
+func calcChromaLimits(depth uint, full bool) (mid, lim float64) {
+    if full {
+        if depth == 0 {
+            return 0.5, 1
+        }
+
+        return float64(uint(1) << (depth - 1)), float64((uint(1) << depth) - 1)
+    }
+
+    if depth == 0 { // floating point
+        return 128.0 / 255.0, 224.0 / 255.0
+    }
+
+    mid = float64(uint(1) << (depth - 1))
+
+    if depth < 8 {
+        return mid, float64(uint(224) >> (8 - depth))
+    }
+
+    return mid, float64(uint(224) << (depth - 8))
+}
+
+// Chromatic adaptation, complete unless Partial is set. Degree is then the degree of adaptation D between 0 (none)
+// and 1 (complete), see DegreeOfAdaptation
 type ChromaticAdapter struct {
     Source, Destination XYZ
     Mode ScalingMode
+    Partial bool
+    Degree float64
 }
 
-type ScalingMode matrix3x3
+type ScalingMode Matrix3x3
 
 var (
     Linear = ScalingMode{1, 0, 0, 0, 1, 0, 0, 0, 1}
     Bradford = ScalingMode{0.8951000, 0.2664000, -0.1614000, -0.7502000, 1.7135000, 0.0367000, 0.0389000, -0.0685000, 1.0296000} // recommended
     VonKries = ScalingMode{0.4002400, 0.7076000, -0.0808100, -0.2263000, 1.1653200, 0.0457000, 0.0000000, 0.0000000, 0.9182200}
+    CAT02 = ScalingMode{0.7328000, 0.4296000, -0.1624000, -0.7036000, 1.6975000, 0.0061000, 0.0030000, 0.0136000, 0.9834000} // CIECAM02
+    CAT16 = ScalingMode{0.4012880, 0.6501730, -0.0514610, -0.2502680, 1.2044140, 0.0458540, -0.0020790, 0.0489520, 0.9531270} // CAM16
+    Sharp = ScalingMode{1.2694000, -0.0988000, -0.1706000, -0.8364000, 1.8006000, 0.0357000, 0.0297000, -0.0315000, 1.0018000}
+    CMCCAT2000 = ScalingMode{0.7982000, 0.3389000, -0.1371000, -0.5918000, 1.5512000, 0.0406000, 0.0008000, 0.0239000, 0.9753000}
 )
 
+func (ca ChromaticAdapter) InputType() Triple {
+    return XYZ{}
+}
+
 func (ca ChromaticAdapter) GetTriple() FilterTriple {
-    forw := matrix3x3(ca.Mode)
+    return adapt(ca.Matrix())
+}
+
+func (ca ChromaticAdapter) linear() LinearFilter {
+    return LinearFilter{ca.Matrix(), Matrix1x3{}, XYZ{}, XYZ{}}
+}
+
+// The adaptation matrix, for XYZ values. Von Kries style adaptation in the cone space of the mode, with partial
+// adaptation the cone gains are blended with a plain luminance scaling (as in CMCCAT2000)
+func (ca ChromaticAdapter) Matrix() Matrix3x3 {
+    D := 1.0
+    if (ca.Partial) {
+        D = ca.Degree
+    }
+
+    forw := Matrix3x3(ca.Mode)
     back := forw.Inverse()
 
-    cs := forw.Mul1x3(matrix1x3{ca.Source.X, ca.Source.Y, ca.Source.Z})
-    cd := forw.Mul1x3(matrix1x3{ca.Destination.X, ca.Destination.Y, ca.Destination.Z})
+    cs := forw.Mul1x3(Matrix1x3{ca.Source.X, ca.Source.Y, ca.Source.Z})
+    cd := forw.Mul1x3(Matrix1x3{ca.Destination.X, ca.Destination.Y, ca.Destination.Z})
+    y := (1 - D) * ca.Destination.Y / ca.Source.Y
 
-    transform := back.Mul3x3(matrix3x3{cd.M1 / cs.M1, 0, 0, 0, cd.M2 / cs.M2, 0, 0, 0, cd.M3 / cs.M3}).Mul3x3(forw)
+    return back.Mul3x3(Matrix3x3{D * cd.M1 / cs.M1 + y, 0, 0, 0, D * cd.M2 / cs.M2 + y, 0, 0, 0, D * cd.M3 / cs.M3 + y}).Mul3x3(forw)
+}
 
+func adapt(transform Matrix3x3) FilterTriple {
     return func(in Triple) Triple {
         var x XYZ
         switch v := in.(type) {
             case XYZ: x = v
             case Yxy: x = v.ToXYZ()
-            default: panic("[ChromaticAdapter] Unsupported color type!")
+            default: panic(unsupported("ChromaticAdapter", in))
         }
-        res := transform.Mul1x3(matrix1x3{x.X, x.Y, x.Z})
+        res := transform.Mul1x3(Matrix1x3{x.X, x.Y, x.Z})
 
         return XYZ{res.M1, res.M2, res.M3}
     }
 }
 
+// Viewing surround, as the factor F of CIECAM02
+type Surround float64
+
+const (
+    SurroundAverage Surround = 1.0
+    SurroundDim Surround = 0.9
+    SurroundDark Surround = 0.8
+)
+
+// Degree of adaptation according to CIECAM02, from the adapting luminance La (in cd/m², usually 20% of the white)
+func DegreeOfAdaptation(La float64, surround Surround) float64 {
+    D := float64(surround) * (1 - math.Exp((-La - 42) / 92) / 3.6)
+
+    return math.Max(0, math.Min(1, D))
+}
+
+// Degree of adaptation according to CMCCAT2000, from the adapting luminances (in cd/m²) of the source and destination
+// fields. Dim and dark surrounds use the same factor of 0.8
+func DegreeOfAdaptationCMCCAT2000(La1, La2 float64, surround Surround) float64 {
+    F := 1.0
+    if (surround < SurroundAverage) {
+        F = 0.8
+    }
+
+    D := F * (0.08 * math.Log10((La1 + La2) / 2) + 0.76 - 0.45 * (La1 - La2) / (La1 + La2))
+
+    return math.Max(0, math.Min(1, D))
+}
+
 // XYZ encoding/decoding
 type XYZSpace Space // Regular Space will auto-apply the gamma transfer function as well
 
 func (c XYZSpace) GetEncoder() FilterTriple {
-    M := matrixFromColorSpace(Space(c)).Inverse()
+    M := Space(c).XYZToRGB()
 
     return func(in Triple) Triple {
         var x XYZ
         switch v := in.(type) {
             case XYZ: x = v
             case Yxy: x = v.ToXYZ()
-            default: panic("[XYZSpace.GetEncoder] Unsupported color type!")
+            default: panic(unsupported("XYZSpace.GetEncoder", in))
         }
 
-        res := M.Mul1x3(matrix1x3{x.X, x.Y, x.Z})
+        res := M.Mul1x3(Matrix1x3{x.X, x.Y, x.Z})
 
         return RGB{res.M1, res.M2, res.M3}
     }
 }
 
 func (c XYZSpace) GetDecoder() FilterTriple {
-    M := matrixFromColorSpace(Space(c))
+    M := Space(c).RGBToXYZ()
 
     return func(in Triple) Triple {
         switch v := in.(type) {
             case RGB:
-                res := M.Mul1x3(matrix1x3{v.R, v.G, v.B})
+                res := M.Mul1x3(Matrix1x3{v.R, v.G, v.B})
                 return XYZ{res.M1, res.M2, res.M3}
-            default: panic("[XYZSpace.GetDecoder] Unsupported color type!")
+            default: panic(unsupported("XYZSpace.GetDecoder", in))
         }
 
         return nil
     }
 }
 
+// Linear versions of the above, Chain fuses these with other linear filters
+func (c XYZSpace) Encoder() LinearFilter {
+    return LinearFilter{Space(c).XYZToRGB(), Matrix1x3{}, XYZ{}, RGB{}}
+}
+
+func (c XYZSpace) Decoder() LinearFilter {
+    return LinearFilter{Space(c).RGBToXYZ(), Matrix1x3{}, RGB{}, XYZ{}}
+}
+
 // Space encoding/decoding, with gamma
 func (c Space) GetEncoder() FilterTriple {
     if (c.Gamma == nil) {
         return XYZSpace(c).GetEncoder()
-    } else {
-        return Chain(XYZSpace(c).GetEncoder(), c.Gamma.GetEncoder()).GetTriple()
     }
-    return nil // Why does the compiler need a return here?
+
+    enc, gamma := XYZSpace(c).GetEncoder(), c.Gamma.GetEncoder().GetTriple()
+    return func(in Triple) Triple {
+        return gamma(enc(in))
+    }
 }
 
 func (c Space) GetDecoder() FilterTriple {
     if (c.Gamma == nil) {
         return XYZSpace(c).GetDecoder()
-    } else {
-        return Chain(c.Gamma.GetDecoder(), XYZSpace(c).GetDecoder()).GetTriple()
     }
-    return nil
+
+    gamma, dec := c.Gamma.GetDecoder().GetTriple(), XYZSpace(c).GetDecoder()
+    return func(in Triple) Triple {
+        return dec(gamma(in))
+    }
+}
+
+// Filter provider versions of the above, as used by Chain
+func (c Space) Encoder() FilterTripleProvider {
+    if (c.Gamma == nil) {
+        return XYZSpace(c).Encoder()
+    }
+    return Chain(XYZSpace(c).Encoder(), c.Gamma.GetEncoder())
 }
 
-// Custom matrix logic
-type matrix1x3 struct {
+func (c Space) Decoder() FilterTripleProvider {
+    if (c.Gamma == nil) {
+        return XYZSpace(c).Decoder()
+    }
+    return Chain(c.Gamma.GetDecoder(), XYZSpace(c).Decoder())
+}
+
+// Matrix logic, matrices can be used as filters on any color type and are fused with other linear filters by Chain
+type Matrix1x3 struct {
     M1, M2, M3 float64
 }
 
-type matrix3x3 struct {
+type Matrix3x3 struct {
     M11, M12, M13,
     M21, M22, M23,
     M31, M32 , M33 float64
 }
 
-func (m matrix3x3) Inverse() matrix3x3 {
+var ErrSingularMatrix = errors.New("[Matrix3x3] Matrix is not invertible")
+
+func (m Matrix3x3) Determinant() float64 {
+    return m.M11 * (m.M33 * m.M22 - m.M32 * m.M23) - m.M21 * (m.M33 * m.M12 - m.M32 * m.M13) + m.M31 * (m.M23 * m.M12 - m.M22 * m.M13)
+}
+
+func (m Matrix3x3) Transpose() Matrix3x3 {
+    return Matrix3x3{m.M11, m.M21, m.M31, m.M12, m.M22, m.M32, m.M13, m.M23, m.M33}
+}
+
+// Inverse without checks, the result of inverting a singular matrix contains infinities or NaNs
+func (m Matrix3x3) Inverse() Matrix3x3 {
     t1 := m.M33 * m.M22 - m.M32 * m.M23
     t2 := m.M33 * m.M12 - m.M32 * m.M13
     t3 := m.M23 * m.M12 - m.M22 * m.M13
     det := m.M11 * t1 - m.M21 * t2 + m.M31 * t3;
 
-    return matrix3x3{t1, -t2, t3,
+    return Matrix3x3{t1, -t2, t3,
            -(m.M33 * m.M21 - m.M31 * m.M23), m.M33 * m.M11 - m.M31 * m.M13, -(m.M23 * m.M11 - m.M21 * m.M13),
            m.M32 * m.M21 - m.M31 * m.M22, -(m.M32 * m.M11 - m.M31 * m.M12), m.M22 * m.M11 - m.M21 * m.M12}.MulC(1 / det)
 }
 
+// Inverse that fails for singular (or nearly singular) matrices
+func (m Matrix3x3) InverseChecked() (Matrix3x3, error) {
+    det := m.Determinant()
+
+    // Relative to the size of the entries, so that scaling the matrix does not change the outcome
+    var norm float64
+    for _, v := range []float64{m.M11, m.M12, m.M13, m.M21, m.M22, m.M23, m.M31, m.M32, m.M33} {
+        norm = math.Max(norm, math.Abs(v))
+    }
+
+    if (norm == 0 || math.IsNaN(det) || math.Abs(det) <= 1e-12 * norm * norm * norm) {
+        return Matrix3x3{}, ErrSingularMatrix
+    }
+
+    return m.Inverse(), nil
+}
+
+func (m Matrix3x3) String() string {
+    return fmt.Sprintf("[[%g %g %g] [%g %g %g] [%g %g %g]]", m.M11, m.M12, m.M13, m.M21, m.M22, m.M23, m.M31, m.M32, m.M33)
+}
+
+func (m Matrix1x3) String() string {
+    return fmt.Sprintf("[%g %g %g]", m.M1, m.M2, m.M3)
+}
+
+// Filter provider implementation, the result has the same type as the input
+func (m Matrix3x3) GetTriple() FilterTriple {
+    return m.linear().GetTriple()
+}
+
+func (m Matrix3x3) linear() LinearFilter {
+    return LinearFilter{m, Matrix1x3{}, nil, nil}
+}
+
 // M * c
-func (m matrix3x3) MulC(c float64) matrix3x3 {
-    return matrix3x3{m.M11 * c, m.M12 * c, m.M13 * c, m.M21 * c, m.M22 * c, m.M23 * c, m.M31 * c, m.M32 * c, m.M33 * c}
+func (m Matrix3x3) MulC(c float64) Matrix3x3 {
+    return Matrix3x3{m.M11 * c, m.M12 * c, m.M13 * c, m.M21 * c, m.M22 * c, m.M23 * c, m.M31 * c, m.M32 * c, m.M33 * c}
 }
 
 // M * M
-func (a matrix3x3) Mul3x3(b matrix3x3) matrix3x3 {
-    return matrix3x3{
+func (a Matrix3x3) Mul3x3(b Matrix3x3) Matrix3x3 {
+    return Matrix3x3{
         a.M11 * b.M11 + a.M12 * b.M21 + a.M13 * b.M31, // C11
         a.M11 * b.M12 + a.M12 * b.M22 + a.M13 * b.M32, // C12
         a.M11 * b.M13 + a.M12 * b.M23 + a.M13 * b.M33, // C13
@@ -184,19 +359,24 @@ func (a matrix3x3) Mul3x3(b matrix3x3) matrix3x3 {
         a.M31 * b.M13 + a.M32 * b.M23 + a.M33 * b.M33} // C23
 }
 
-func (a matrix3x3) Mul1x3(b matrix1x3) matrix1x3 {
-    return matrix1x3{
+func (a Matrix3x3) Mul1x3(b Matrix1x3) Matrix1x3 {
+    return Matrix1x3{
         a.M11 * b.M1 + a.M12 * b.M2 + a.M13 * b.M3,
         a.M21 * b.M1 + a.M22 * b.M2 + a.M23 * b.M3,
         a.M31 * b.M1 + a.M32 * b.M2 + a.M33 * b.M3}
 }
 
-func matrixFromColorSpace(c Space) matrix3x3 {
-    S := matrix3x3{c.Red.X, c.Green.X, c.Blue.X,
+// Matrices for converting linear RGB values in the space to XYZ and back
+func (c Space) RGBToXYZ() Matrix3x3 {
+    S := Matrix3x3{c.Red.X, c.Green.X, c.Blue.X,
                    c.Red.Y, c.Green.Y, c.Blue.Y,
-                   c.Red.Z, c.Green.Z, c.Blue.Z}.Inverse().Mul1x3(matrix1x3{c.White.X, c.White.Y, c.White.Z})
+                   c.Red.Z, c.Green.Z, c.Blue.Z}.Inverse().Mul1x3(Matrix1x3{c.White.X, c.White.Y, c.White.Z})
 
-    return matrix3x3{S.M1 * c.Red.X, S.M2 * c.Green.X, S.M3 * c.Blue.X,
+    return Matrix3x3{S.M1 * c.Red.X, S.M2 * c.Green.X, S.M3 * c.Blue.X,
                      S.M1 * c.Red.Y, S.M2 * c.Green.Y, S.M3 * c.Blue.Y,
                      S.M1 * c.Red.Z, S.M2 * c.Green.Z, S.M3 * c.Blue.Z}
 }
+
+func (c Space) XYZToRGB() Matrix3x3 {
+    return c.RGBToXYZ().Inverse()
+}
@@ -1,17 +1,99 @@
 package colorplus
 
-import "testing"
+import (
+    "errors"
+    "math"
+    "testing"
+)
 
 func TestCalculations(t *testing.T) {
     FuzzyAssertTriple(6503.6, FromTemperature(6503.6), PointD65, allow * 100, "FromTemperature", t) // not as precise, lots of conversions
+    FuzzyAssertSingle(Lab{0.532408, 0.800925, 0.672032}, Luminance(Lab{0.532408, 0.800925, 0.672032}), 0.212673, allow, "Luminance", t)
+    FuzzyAssertSingle(1000, HLGSystemGamma(1000), 1.2, allow, "HLGSystemGamma", t)
     FuzzyAssertSingle("", SpacesRGB.Area(), 0.11205, allow, "SpacesRGB.Area", t)
 
     // Matrix testing
-    m := matrixFromColorSpace(SpacesRGB)
-    a := matrix3x3{0.4124564, 0.35757, 0.1804374, 0.2126728, 0.71515, 0.0721749, 0.0193338, 0.11919, 0.9503040}
+    m := SpacesRGB.RGBToXYZ()
+    a := Matrix3x3{0.4124564, 0.35757, 0.1804374, 0.2126728, 0.71515, 0.0721749, 0.0193338, 0.11919, 0.9503040}
 
     if !FuzzyCompareMatrix3x3(a, m, allow) {
-        t.Errorf("matrixFromColorSpace(SpacesRGB) = %v, want %v.", m, a)
+        t.Errorf("SpacesRGB.RGBToXYZ() = %v, want %v.", m, a)
+    }
+
+    m = SpaceBT2020.RGBToXYZ()
+    a = Matrix3x3{0.636958, 0.144617, 0.168881, 0.262700, 0.677998, 0.059302, 0, 0.028073, 1.060985}
+
+    if !FuzzyCompareMatrix3x3(a, m, allow * 100) {
+        t.Errorf("SpaceBT2020.RGBToXYZ() = %v, want %v.", m, a)
+    }
+
+    m = SpaceACEScg.RGBToXYZ()
+    a = Matrix3x3{0.6624542, 0.1340042, 0.1561877, 0.2722287, 0.6740818, 0.0536895, -0.0055746, 0.0040607, 1.0103391}
+
+    if !FuzzyCompareMatrix3x3(a, m, allow) {
+        t.Errorf("SpaceACEScg.RGBToXYZ() = %v, want %v.", m, a)
+    }
+
+    // Matrix helpers
+    m = SpacesRGB.XYZToRGB().Mul3x3(SpacesRGB.RGBToXYZ())
+    if !FuzzyCompareMatrix3x3(m, Matrix3x3(Linear), allow) {
+        t.Errorf("SpacesRGB.XYZToRGB() * SpacesRGB.RGBToXYZ() = %v, want %v.", m, Matrix3x3(Linear))
+    }
+
+    a = Matrix3x3{1, 2, 3, 4, 5, 6, 7, 8, 10}
+    FuzzyAssertSingle(a, a.Determinant(), -3, allow, "Determinant", t)
+    FuzzyAssertSingle(a, a.Transpose().M12, 4, allow, "Transpose", t)
+
+    if _, err := (Matrix3x3{1, 2, 3, 4, 5, 6, 7, 8, 9}).InverseChecked(); err != ErrSingularMatrix {
+        t.Errorf("InverseChecked(singular) = %v, want %v.", err, ErrSingularMatrix)
+    }
+
+    if s := (Matrix3x3{1, 0, 0, 0, 1, 0, 0, 0, 0.5}).String(); s != "[[1 0 0] [0 1 0] [0 0 0.5]]" {
+        t.Errorf("String() = %v, want %v.", s, "[[1 0 0] [0 1 0] [0 0 0.5]]")
+    }
+
+    adapter := ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}
+    FuzzyAssertTriple(PointD65, adapter.Matrix().GetTriple()(PointD65), PointD50, allow, "ChromaticAdapter.Matrix()", t)
+    FuzzyAssertTriple(RGB{1, 1, 1}, Chain(SpacesRGB.RGBToXYZ()).GetTriple()(RGB{1, 1, 1}), RGB{PointD65.X, PointD65.Y, PointD65.Z}, allow, "Chain(SpacesRGB.RGBToXYZ())", t)
+
+    // Adaptation testing
+    for _, mode := range []ScalingMode{Bradford, CAT02, CAT16, Sharp, CMCCAT2000} {
+        FuzzyAssertTriple(PointD65, ChromaticAdapter{PointD65, PointA, mode, false, 0}.GetTriple()(PointD65), PointA, allow, "ChromaticAdapter", t)
+    }
+
+    // Partial adaptation of a non-white sample, worked example from the CMCCAT2000 paper (Li et al., 2002)
+    D := DegreeOfAdaptationCMCCAT2000(200, 200, SurroundAverage)
+    FuzzyAssertSingle(200, D, 0.944082, allow, "DegreeOfAdaptationCMCCAT2000", t)
+
+    partial := ChromaticAdapter{XYZ{1.1115, 1, 0.3520}, XYZ{0.9481, 1, 1.0730}, CMCCAT2000, true, D}.GetTriple()
+    FuzzyAssertTriple(XYZ{0.2248, 0.2274, 0.0854}, partial(XYZ{0.2248, 0.2274, 0.0854}), XYZ{0.195270, 0.230683, 0.249718}, allow, "ChromaticAdapter(CMCCAT2000, D < 1)", t)
+
+    // Adaptation is complete unless partial, then a degree of 0 means none
+    FuzzyAssertTriple(PointD65, ChromaticAdapter{Source: PointD65, Destination: PointA, Mode: CAT02}.GetTriple()(PointD65), PointA, allow, "ChromaticAdapter(complete)", t)
+    FuzzyAssertTriple(PointD65, ChromaticAdapter{PointD65, PointA, CAT02, true, 0}.GetTriple()(PointD65), PointD65, allow, "ChromaticAdapter(D = 0)", t)
+    FuzzyAssertTriple(PointD65, ChromaticAdapter{PointD65, PointA, CAT02, true, 1}.GetTriple()(PointD65), PointA, allow, "ChromaticAdapter(D = 1)", t)
+    FuzzyAssertSingle(1000, DegreeOfAdaptationCMCCAT2000(1000, 10, SurroundDim), 0.8 * (0.08 * math.Log10(505) + 0.76 - 0.45 * 990 / 1010), allow, "DegreeOfAdaptationCMCCAT2000", t)
+    FuzzyAssertSingle(318.31, DegreeOfAdaptation(318.31, SurroundAverage), 0.994468, allow, "DegreeOfAdaptation", t)
+    FuzzyAssertSingle(0, DegreeOfAdaptation(0, SurroundDark), 0.8 * (1 - math.Exp(-42.0 / 92) / 3.6), allow, "DegreeOfAdaptation", t)
+
+    // Color temperature testing
+    planck, _ := PlanckianLocus(2856)
+    FuzzyAssertTriple(2856, planck, PointA, allow * 100, "PlanckianLocus", t)
+
+    for _, c := range [][2]float64{{6500, 0.003}, {1500, -0.01}, {3200, 0}, {50000, 0.02}} {
+        w, _ := FromCCT(c[0], c[1])
+        T, Duv, err := CCT(w)
+        if (err != nil || !FuzzyCompareSingle(T, c[0], c[0] * 1e-4) || !FuzzyCompareSingle(Duv, c[1], 1e-5)) {
+            t.Errorf("CCT(FromCCT(%v, %v)) = %v, %v, %v.", c[0], c[1], T, Duv, err)
+        }
+    }
+
+    if T, Duv, _ := CCT(PointD65); (!FuzzyCompareSingle(T, 6504, 5) || !FuzzyCompareSingle(Duv, 0.0032, 1e-4)) {
+        t.Errorf("CCT(PointD65) = %v, %v, want %v, %v.", T, Duv, 6504, 0.0032)
+    }
+
+    if _, _, err := CCT(Yxy{1, 0.2, 0.7}); !errors.Is(err, ErrOutOfRange) {
+        t.Errorf("CCT(green) = %v, want %v.", err, ErrOutOfRange)
     }
 
     // Color space area testing
@@ -49,6 +49,8 @@ var (
     PointF10 = Yxy{1, 0.34609, 0.35986}.ToXYZ()
     PointF11 = Yxy{1, 0.38052, 0.37713}.ToXYZ()
     PointF12 = Yxy{1, 0.43695, 0.40441}.ToXYZ()
+    PointDCI = Yxy{1, 0.314, 0.351}.ToXYZ()
+    PointACES = Yxy{1, 0.32168, 0.33767}.ToXYZ()
     PointZero = Yxy{1, 0, 0}.ToXYZ()
 )
 
@@ -64,7 +66,10 @@ var (
     SpaceSECAM = SpaceFromxy(0.64, 0.33, 0.29, 0.60, 0.15, 0.06, PointD65, PurePowerCurve{2.8})
     SpaceAdobeWideRGB = SpaceFromxy(0.735, 0.265, 0.115, 0.826, 0.157, 0.018, PointD50, nil)
     SpaceCIE1931 = SpaceFromxy(0.7347, 0.2653, 0.2738, 0.7174, 0.1666, 0.0089, PointE, nil)
-    SpaceACES = SpaceFromxy(0.73470, 0.26530, 0, 1, 0.00010, -0.07700, Yxy{1, 0.32168, 0.33767}.ToXYZ(), nil)
+    SpaceBT2020 = SpaceFromxy(0.708, 0.292, 0.170, 0.797, 0.131, 0.046, PointD65, BT1886Curve{100, 0, false})
+    SpaceDCI_P3 = SpaceFromxy(0.680, 0.320, 0.265, 0.690, 0.150, 0.060, PointDCI, PurePowerCurve{2.6})
+    SpaceACES_AP0 = SpaceFromxy(0.73470, 0.26530, 0, 1, 0.00010, -0.07700, PointACES, nil)
+    SpaceACES_AP1 = SpaceFromxy(0.713, 0.293, 0.165, 0.830, 0.128, 0.044, PointACES, nil)
 
     // some aliases
     SpaceNone = SpaceZero
@@ -74,6 +79,14 @@ var (
     SpacescRGB = SpacesRGB
     SpacemadVR = SpaceFromExisting(SpaceBT709, PurePowerCurve{2.2})
 
+    SpaceBT2100_PQ = SpaceFromExisting(SpaceBT2020, PQCurve{})
+    SpaceBT2100_HLG = SpaceFromExisting(SpaceBT2020, HLGCurve{})
+    SpaceDisplayP3 = Space{SpaceDCI_P3.Red, SpaceDCI_P3.Green, SpaceDCI_P3.Blue, PointD65, SRGBCurve}
+
+    SpaceACES = SpaceACES_AP0
+    SpaceACES2065_1 = SpaceACES_AP0
+    SpaceACEScg = SpaceACES_AP1
+
     SpaceProPhotoRGB = SpaceROMM
 
     SpaceFCC1953 = SpaceNTSC_53
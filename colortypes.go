@@ -51,3 +51,82 @@ func (in RGB) Get() (a, b, c float64) {
 func (in RGB) Make(a, b, c float64) Triple {
     return RGB{a, b, c}
 }
+
+// CIE 1976 L*a*b* (derived from XYZ relative to a reference white)
+// Note: L* is normalized to 0-1 like the output of LStarCurve, a* and b* are scaled down by the same factor of 100
+type Lab struct {
+    L, A, B float64
+}
+
+func (in Lab) Get() (a, b, c float64) {
+    return in.L, in.A, in.B
+}
+
+func (_ Lab) Make(a, b, c float64) Triple {
+    return Lab{a, b, c}
+}
+
+// CIE L*C*h (cylindrical form of L*a*b*), the hue angle is normalized to 0-1 as a fraction of a full turn
+type LCh struct {
+    L, C, H float64
+}
+
+func (in LCh) Get() (a, b, c float64) {
+    return in.L, in.C, in.H
+}
+
+func (_ LCh) Make(a, b, c float64) Triple {
+    return LCh{a, b, c}
+}
+
+// CIE 1976 Yu'v' (derived from XYZ, like Yxy but on the uniform chromaticity scale)
+type Yuv struct {
+    Y, U, V float64
+}
+
+func (in Yuv) Get() (a, b, c float64) {
+    return in.Y, in.U, in.V
+}
+
+func (_ Yuv) Make(a, b, c float64) Triple {
+    return Yuv{a, b, c}
+}
+
+// CIE 1976 L*u*v* (derived from XYZ relative to a reference white), normalized the same way as Lab
+type Luv struct {
+    L, U, V float64
+}
+
+func (in Luv) Get() (a, b, c float64) {
+    return in.L, in.U, in.V
+}
+
+func (_ Luv) Make(a, b, c float64) Triple {
+    return Luv{a, b, c}
+}
+
+// CIE L*C*h(uv) (cylindrical form of L*u*v*), the hue angle is normalized the same way as LCh
+type LChuv struct {
+    L, C, H float64
+}
+
+func (in LChuv) Get() (a, b, c float64) {
+    return in.L, in.C, in.H
+}
+
+func (_ LChuv) Make(a, b, c float64) Triple {
+    return LChuv{a, b, c}
+}
+
+// Y'CbCr (derived from R'G'B' by a YCbCrMatrix), Y is normalized to 0-1 and the chroma channels to -0.5-0.5
+type YCbCr struct {
+    Y, Cb, Cr float64
+}
+
+func (in YCbCr) Get() (a, b, c float64) {
+    return in.Y, in.Cb, in.Cr
+}
+
+func (_ YCbCr) Make(a, b, c float64) Triple {
+    return YCbCr{a, b, c}
+}
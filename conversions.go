@@ -1,5 +1,12 @@
 package colorplus
 
+import (
+    "errors"
+    "fmt"
+    "math"
+    "reflect"
+)
+
 // From XYZ
 func (in XYZ) ToYxy() Yxy {
     lum := in.X + in.Y + in.Z
@@ -7,16 +14,373 @@ func (in XYZ) ToYxy() Yxy {
     return Yxy{in.Y, in.X / lum, in.Y / lum}
 }
 
+func (in XYZ) ToLab(white XYZ, mode LStarCurve) Lab {
+    fx, fy, fz := mode.labF(in.X / white.X), mode.labF(in.Y / white.Y), mode.labF(in.Z / white.Z)
+
+    return Lab{1.16 * fy - 0.16, 5 * (fx - fy), 2 * (fy - fz)}
+}
+
+func (in XYZ) ToYuv() Yuv {
+    d := in.X + 15 * in.Y + 3 * in.Z
+
+    return Yuv{in.Y, 4 * in.X / d, 9 * in.Y / d}
+}
+
+func (in XYZ) ToLuv(white XYZ, mode LStarCurve) Luv {
+    if (in.Y == 0) { // u'v' is undefined for black
+        return Luv{0, 0, 0}
+    }
+
+    L := 1.16 * mode.labF(in.Y / white.Y) - 0.16
+    c, w := in.ToYuv(), white.ToYuv()
+
+    return Luv{L, 13 * L * (c.U - w.U), 13 * L * (c.V - w.V)}
+}
+
 // From Yxy
 func (in Yxy) ToXYZ() XYZ {
     return XYZ{in.Y * in.x / in.y, in.Y, in.Y * (1 - in.x - in.y) / in.y}
 }
 
+// From Lab
+func (in Lab) ToXYZ(white XYZ, mode LStarCurve) XYZ {
+    fy := (in.L + 0.16) / 1.16
+    fx, fz := fy + in.A / 5, fy - in.B / 2
+
+    return XYZ{white.X * mode.labFInverse(fx), white.Y * mode.labFInverse(fy), white.Z * mode.labFInverse(fz)}
+}
+
+func (in Lab) ToLCh() LCh {
+    c, h := toPolar(in.A, in.B)
+
+    return LCh{in.L, c, h}
+}
+
+// From LCh
+func (in LCh) ToLab() Lab {
+    a, b := fromPolar(in.C, in.H)
+
+    return Lab{in.L, a, b}
+}
+
+// From Yuv
+func (in Yuv) ToXYZ() XYZ {
+    return XYZ{in.Y * 9 * in.U / (4 * in.V), in.Y, in.Y * (12 - 3 * in.U - 20 * in.V) / (4 * in.V)}
+}
+
+// From Luv
+func (in Luv) ToXYZ(white XYZ, mode LStarCurve) XYZ {
+    if (in.L <= 0) {
+        return XYZ{0, 0, 0}
+    }
+
+    w := white.ToYuv()
+    Y := white.Y * mode.labFInverse((in.L + 0.16) / 1.16)
+
+    return Yuv{Y, in.U / (13 * in.L) + w.U, in.V / (13 * in.L) + w.V}.ToXYZ()
+}
+
+func (in Luv) ToLChuv() LChuv {
+    c, h := toPolar(in.U, in.V)
+
+    return LChuv{in.L, c, h}
+}
+
+// From LChuv
+func (in LChuv) ToLuv() Luv {
+    u, v := fromPolar(in.C, in.H)
+
+    return Luv{in.L, u, v}
+}
+
+// Cartesian <-> cylindrical coordinates, with the hue angle normalized to 0-1
+func toPolar(a, b float64) (c, h float64) {
+    h = math.Atan2(b, a) / (2 * math.Pi)
+    if (h < 0) {
+        h += 1
+    }
+
+    return math.Hypot(a, b), h
+}
+
+func fromPolar(c, h float64) (a, b float64) {
+    s, co := math.Sincos(h * 2 * math.Pi)
+
+    return c * co, c * s
+}
+
 // Conversion filters
 var XYZtoYxy = FilterTriple(func(in Triple) Triple {
-    return in.(XYZ).ToYxy()
+    switch v := in.(type) {
+        case XYZ: return v.ToYxy()
+        default: panic(unsupported("XYZtoYxy", in))
+    }
 })
 
 var YxytoXYZ = FilterTriple(func(in Triple) Triple {
-    return in.(Yxy).ToXYZ()
+    switch v := in.(type) {
+        case Yxy: return v.ToXYZ()
+        default: panic(unsupported("YxytoXYZ", in))
+    }
+})
+
+var XYZtoYuv = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case XYZ: return v.ToYuv()
+        default: panic(unsupported("XYZtoYuv", in))
+    }
 })
+
+var YuvtoXYZ = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case Yuv: return v.ToXYZ()
+        default: panic(unsupported("YuvtoXYZ", in))
+    }
+})
+
+var LabtoLCh = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case Lab: return v.ToLCh()
+        default: panic(unsupported("LabtoLCh", in))
+    }
+})
+
+var LChtoLab = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case LCh: return v.ToLab()
+        default: panic(unsupported("LChtoLab", in))
+    }
+})
+
+// L*a*b* encoding/decoding relative to a reference white
+type LabSpace struct {
+    White XYZ
+    Mode LStarCurve
+}
+
+func (ls LabSpace) GetEncoder() FilterTriple {
+    return func(in Triple) Triple {
+        var x XYZ
+        switch v := in.(type) {
+            case XYZ: x = v
+            case Yxy: x = v.ToXYZ()
+            default: panic(unsupported("LabSpace.GetEncoder", in))
+        }
+
+        return x.ToLab(ls.White, ls.Mode)
+    }
+}
+
+func (ls LabSpace) GetDecoder() FilterTriple {
+    return func(in Triple) Triple {
+        switch v := in.(type) {
+            case Lab: return v.ToXYZ(ls.White, ls.Mode)
+            case LCh: return v.ToLab().ToXYZ(ls.White, ls.Mode)
+            default: panic(unsupported("LabSpace.GetDecoder", in))
+        }
+    }
+}
+
+var LuvtoLChuv = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case Luv: return v.ToLChuv()
+        default: panic(unsupported("LuvtoLChuv", in))
+    }
+})
+
+var LChuvtoLuv = FilterTriple(func(in Triple) Triple {
+    switch v := in.(type) {
+        case LChuv: return v.ToLuv()
+        default: panic(unsupported("LChuvtoLuv", in))
+    }
+})
+
+// L*u*v* encoding/decoding relative to a reference white
+type LuvSpace struct {
+    White XYZ
+    Mode LStarCurve
+}
+
+func (ls LuvSpace) GetEncoder() FilterTriple {
+    return func(in Triple) Triple {
+        var x XYZ
+        switch v := in.(type) {
+            case XYZ: x = v
+            case Yxy: x = v.ToXYZ()
+            case Yuv: x = v.ToXYZ()
+            default: panic(unsupported("LuvSpace.GetEncoder", in))
+        }
+
+        return x.ToLuv(ls.White, ls.Mode)
+    }
+}
+
+func (ls LuvSpace) GetDecoder() FilterTriple {
+    return func(in Triple) Triple {
+        switch v := in.(type) {
+            case Luv: return v.ToXYZ(ls.White, ls.Mode)
+            case LChuv: return v.ToLuv().ToXYZ(ls.White, ls.Mode)
+            default: panic(unsupported("LuvSpace.GetDecoder", in))
+        }
+    }
+}
+
+// Y'CbCr encoding/decoding, defined by the luma coefficients of red and blue. Operates on non-linear R'G'B'
+type YCbCrMatrix struct {
+    Kr, Kb float64
+}
+
+var (
+    YCbCrBT601 = YCbCrMatrix{0.299, 0.114}
+    YCbCrBT709 = YCbCrMatrix{0.2126, 0.0722}
+    YCbCrBT2020 = YCbCrMatrix{0.2627, 0.0593} // non-constant luminance
+)
+
+func (m YCbCrMatrix) GetEncoder() FilterTriple {
+    Kg := 1 - m.Kr - m.Kb
+
+    return func(in Triple) Triple {
+        switch v := in.(type) {
+            case RGB:
+                Y := m.Kr * v.R + Kg * v.G + m.Kb * v.B
+                return YCbCr{Y, (v.B - Y) / (2 * (1 - m.Kb)), (v.R - Y) / (2 * (1 - m.Kr))}
+            default: panic(unsupported("YCbCrMatrix.GetEncoder", in))
+        }
+    }
+}
+
+func (m YCbCrMatrix) GetDecoder() FilterTriple {
+    Kg := 1 - m.Kr - m.Kb
+
+    return func(in Triple) Triple {
+        switch v := in.(type) {
+            case YCbCr:
+                R, B := v.Y + 2 * (1 - m.Kr) * v.Cr, v.Y + 2 * (1 - m.Kb) * v.Cb
+                return RGB{R, (v.Y - m.Kr * R - m.Kb * B) / Kg, B}
+            default: panic(unsupported("YCbCrMatrix.GetDecoder", in))
+        }
+    }
+}
+
+// Parameters for automatic conversions between color types
+type Converter struct {
+    Space Space         // for RGB <-> XYZ, including the transfer curve if any
+    White XYZ           // reference white for L*a*b* and L*u*v*, the white point of Space is used if zero
+    Mode LStarCurve
+    Matrix YCbCrMatrix  // for Y'CbCr <-> R'G'B'
+}
+
+// Used by Chain and Convert
+var DefaultConverter = Converter{SpacesRGB, XYZ{}, LStarIntent, YCbCrBT709}
+
+// Convert a color to the type of target, using DefaultConverter
+func Convert(in, target Triple) Triple {
+    return DefaultConverter.Convert(in, target)
+}
+
+func (c Converter) Convert(in, target Triple) Triple {
+    f, err := c.GetConversion(in, target)
+    if err != nil {
+        panic(err)
+    }
+
+    return f(in)
+}
+
+// Build a filter converting colors of the type of from to the type of to, along the shortest path of conversions
+func (c Converter) GetConversion(from, to Triple) (FilterTriple, error) {
+    want := reflect.TypeOf(to)
+
+    if (reflect.TypeOf(from) == want) {
+        return func(in Triple) Triple { return in }, nil
+    }
+
+    for _, r := range c.reachable(from) {
+        if (reflect.TypeOf(r.to) == want) {
+            return r.f, nil
+        }
+    }
+
+    return nil, fmt.Errorf("[Converter] %w: no conversion from %T to %T", ErrUnsupportedType, from, to)
+}
+
+// The conversion needed in front of f for inputs like in, nil if f supports the type of in. The conversion is to the
+// input type f declares, or else to the closest type f supports. Unknown inputs (nil) are never converted
+func (c Converter) conversionFor(f interface{}, in Triple) (conv FilterTriple, to Triple, err error) {
+    if _, _, err = getTripleChecked(f, in); in == nil || !errors.Is(err, ErrUnsupportedType) {
+        return nil, in, nil // other errors are reported when f is built
+    }
+
+    if t := inputTypeOf(f); t != nil {
+        conv, err = c.GetConversion(in, t)
+        return conv, t, err
+    }
+
+    for _, r := range c.reachable(in) {
+        if _, _, rerr := getTripleChecked(f, r.to); rerr == nil {
+            return r.f, r.to, nil
+        }
+    }
+
+    return nil, nil, err
+}
+
+// The conversion graph
+type conversion struct {
+    to Triple
+    f FilterTriple
+}
+
+func (c Converter) edges(from Triple) []conversion {
+    white := c.White
+    if (white == XYZ{}) {
+        white = c.Space.White
+    }
+
+    lab, luv := LabSpace{white, c.Mode}, LuvSpace{white, c.Mode}
+
+    switch from.(type) {
+        case XYZ: return []conversion{{Yxy{}, XYZtoYxy}, {Yuv{}, XYZtoYuv}, {Lab{}, lab.GetEncoder()}, {Luv{}, luv.GetEncoder()}, {RGB{}, c.Space.GetEncoder()}}
+        case Yxy: return []conversion{{XYZ{}, YxytoXYZ}}
+        case Yuv: return []conversion{{XYZ{}, YuvtoXYZ}}
+        case Lab: return []conversion{{XYZ{}, lab.GetDecoder()}, {LCh{}, LabtoLCh}}
+        case LCh: return []conversion{{Lab{}, LChtoLab}}
+        case Luv: return []conversion{{XYZ{}, luv.GetDecoder()}, {LChuv{}, LuvtoLChuv}}
+        case LChuv: return []conversion{{Luv{}, LChuvtoLuv}}
+        case RGB: return []conversion{{XYZ{}, c.Space.GetDecoder()}, {YCbCr{}, c.Matrix.GetEncoder()}}
+        case YCbCr: return []conversion{{RGB{}, c.Matrix.GetDecoder()}}
+    }
+
+    return nil
+}
+
+// All types reachable from the type of in, in order of increasing distance, with the conversion to each
+func (c Converter) reachable(in Triple) []conversion {
+    seen := map[reflect.Type]bool{reflect.TypeOf(in): true}
+    queue := []conversion{{in, nil}}
+    var res []conversion
+
+    for len(queue) > 0 {
+        cur := queue[0]
+        queue = queue[1:]
+
+        for _, e := range c.edges(cur.to) {
+            t := reflect.TypeOf(e.to)
+            if seen[t] {
+                continue
+            }
+            seen[t] = true
+
+            next := conversion{e.to, e.f}
+            if (cur.f != nil) {
+                first, second := cur.f, e.f
+                next.f = func(in Triple) Triple { return second(first(in)) }
+            }
+
+            res = append(res, next)
+            queue = append(queue, next)
+        }
+    }
+
+    return res
+}
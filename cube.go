@@ -0,0 +1,353 @@
+package colorplus
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Sampled lattice as used by the Adobe/Resolve .cube format, either a 1D LUT (one entry per input value, applied to
+// each channel separately) or a 3D LUT (one entry per input triple). Input values are mapped linearly from the range
+// DomainMin-DomainMax onto the lattice, and outputs are normalized into the range 0-1 like all other color values
+type CubeLUT struct {
+    Title string                    // written between double quotes as is, must not contain a line break
+    Comments []string
+    Dimensions int                  // 1 or 3
+    Size int                        // number of entries along each axis
+    DomainMin, DomainMax RGB
+    Data []RGB                      // Size or Size³ entries, red varies fastest, then green, then blue
+    Interpolation InterpolationMode // used by 3D LUTs, 1D LUTs are always interpolated linearly
+}
+
+var (
+    ErrInvalidCube = errors.New("[CubeLUT] Invalid .cube file")
+    ErrUnsupportedEncoding = errors.New("[CubeLUT] Only BGR encoding can be converted")
+    ErrInvalidCubeSize = errors.New("[CubeLUT] Invalid dimensions or size")
+)
+
+// Create an empty .cube LUT covering the domain 0-1
+func MakeCubeLUT(dimensions, size int) *CubeLUT {
+    cube, err := MakeCubeLUTChecked(dimensions, size)
+    if err != nil {
+        panic(err)
+    }
+
+    return cube
+}
+
+// Same limits as ReadCubeLUT
+func MakeCubeLUTChecked(dimensions, size int) (*CubeLUT, error) {
+    if ((dimensions != 1 && dimensions != 3) || size < 2 || size > 65536 || (dimensions == 3 && size > 256)) {
+        return nil, ErrInvalidCubeSize
+    }
+
+    n := size
+    if (dimensions == 3) {
+        n = size * size * size
+    }
+
+    return &CubeLUT{Dimensions: dimensions, Size: size, DomainMax: RGB{1, 1, 1}, Data: make([]RGB, n)}, nil
+}
+
+// Assignment logic, samples the filter at every lattice point. The filter must accept and return RGB, a panic in the
+// filter is returned as an error
+func (cube *CubeLUT) Assign(filter FilterTripleProvider) (err error) {
+    f, out, err := getTripleChecked(filter, RGB{})
+    if err != nil {
+        return err
+    }
+
+    defer recoverError(&err)
+
+    if _, ok := out.(RGB); !ok && out != nil {
+        return returnsNonRGB(out)
+    }
+
+    step := cube.step()
+
+    if (cube.Dimensions == 1) {
+        for i := range cube.Data {
+            cube.Data[i] = assignRGB(f(cube.point(i, i, i, step)))
+        }
+        return nil
+    }
+
+    pos := 0
+    for b := 0; b < cube.Size; b++ {
+        for g := 0; g < cube.Size; g++ {
+            for r := 0; r < cube.Size; r++ {
+                cube.Data[pos] = assignRGB(f(cube.point(r, g, b, step)))
+                pos++
+            }
+        }
+    }
+
+    return nil
+}
+
+// Filters without a declared output type are checked on each result
+func assignRGB(res Triple) RGB {
+    rgb, ok := res.(RGB)
+    if (!ok) {
+        panic(returnsNonRGB(res))
+    }
+    return rgb
+}
+
+func returnsNonRGB(out Triple) error {
+    return fmt.Errorf("[CubeLUT] %w: filter returns %T", ErrUnsupportedType, out)
+}
+
+// Filter provider implementation, interpolates between lattice points
+func (cube *CubeLUT) GetTriple() FilterTriple {
+    n := cube.Size - 1
+    step := cube.step()
+
+    return func(in Triple) Triple {
+        a, b, c := in.Get()
+        pr := cube.index(a, cube.DomainMin.R, step.R)
+        pg := cube.index(b, cube.DomainMin.G, step.G)
+        pb := cube.index(c, cube.DomainMin.B, step.B)
+
+        if (cube.Dimensions == 1) {
+            return in.Make(lerp1D(cube.Data, pr, 0), lerp1D(cube.Data, pg, 1), lerp1D(cube.Data, pb, 2))
+        }
+
+        return in.Make(interpolate3D(cube.Interpolation, func(r, g, b int) (float64, float64, float64) {
+            return cube.Data[r + (g + b * (n + 1)) * (n + 1)].Get()
+        }, [3]int{n, n, n}, [3]float64{pr, pg, pb}))
+    }
+}
+
+func (cube *CubeLUT) InputType() Triple {
+    return RGB{}
+}
+
+// Conversion to a LUT3D, by sampling the .cube LUT at every point of the LUT3D
+func (cube *CubeLUT) ToLUT3D(InputDepth []int32, OutputDepth int32, InputRange, OutputRange int32, InputSpace, OutputSpace Space) *LUT3D {
+    lut := Make3DLUT(InputDepth, OutputDepth, InputRange, OutputRange, EncodingBGR, EncodingBGR, InputSpace, OutputSpace)
+    lut.Assign(cube, true)
+
+    return lut
+}
+
+// Conversion from a LUT3D, which must use BGR encoding and the same bit depth for every input channel
+func CubeFromLUT3D(lut *LUT3D) (*CubeLUT, error) {
+    if (lut.InputColorEncoding != EncodingBGR || lut.OutputColorEncoding != EncodingBGR) {
+        return nil, ErrUnsupportedEncoding
+    }
+
+    depth := lut.InputBitDepth[0]
+    if (lut.InputBitDepth[1] != depth || lut.InputBitDepth[2] != depth) {
+        return nil, ErrInvalidBitDepth
+    }
+
+    cube := MakeCubeLUT(3, 1 << uint(depth))
+    pulldown := lut.outputRange(false).GetTriple()
+
+    // The lattice covers every integer code, which for limited range extends beyond 0-1
+    bot, lim := calcLimits(uint(depth), lut.InputValueRange == RangeFull)
+    min, max := -bot / lim, (float64(cube.Size - 1) - bot) / lim
+    cube.DomainMin, cube.DomainMax = RGB{min, min, min}, RGB{max, max, max}
+
+    pos := 0
+    for b := 0; b < cube.Size; b++ {
+        for g := 0; g < cube.Size; g++ {
+            for r := 0; r < cube.Size; r++ {
+                cube.Data[pos] = pulldown(lut.GetOutputRaw(lut.Offset(b, g, r))).(RGB)
+                pos++
+            }
+        }
+    }
+
+    return cube, nil
+}
+
+// Write the LUT in .cube format to w, implements io.WriterTo
+func (cube *CubeLUT) WriteTo(w io.Writer) (int64, error) {
+    cw := &countingWriter{w: w}
+    bw := bufio.NewWriter(cw)
+
+    for _, c := range cube.Comments {
+        fmt.Fprintf(bw, "# %s\n", c)
+    }
+
+    if (cube.Title != "") {
+        fmt.Fprintf(bw, "TITLE \"%s\"\n", cube.Title)
+    }
+
+    fmt.Fprintf(bw, "LUT_%dD_SIZE %d\n", cube.Dimensions, cube.Size)
+    fmt.Fprintf(bw, "DOMAIN_MIN %s\n", formatCubeRGB(cube.DomainMin))
+    fmt.Fprintf(bw, "DOMAIN_MAX %s\n", formatCubeRGB(cube.DomainMax))
+
+    for _, v := range cube.Data {
+        fmt.Fprintf(bw, "%s\n", formatCubeRGB(v))
+    }
+
+    err := bw.Flush()
+    return cw.n, err
+}
+
+// Read a LUT in .cube format from r
+func ReadCubeLUT(r io.Reader) (*CubeLUT, error) {
+    cube := &CubeLUT{DomainMax: RGB{1, 1, 1}}
+    scanner := bufio.NewScanner(r)
+    line := 0
+
+    fail := func() (*CubeLUT, error) {
+        return nil, fmt.Errorf("%w (line %d)", ErrInvalidCube, line)
+    }
+
+    for scanner.Scan() {
+        line++
+        text := strings.TrimSpace(scanner.Text())
+
+        if (text == "") {
+            continue
+        }
+
+        if (text[0] == '#') {
+            cube.Comments = append(cube.Comments, strings.TrimSpace(text[1:]))
+            continue
+        }
+
+        fields := strings.Fields(text)
+
+        switch fields[0] {
+            case "TITLE":
+                // Everything between the first and the last quote, no escapes
+                title := strings.TrimSpace(text[len("TITLE"):])
+                if (len(title) < 2 || title[0] != '"' || title[len(title) - 1] != '"') {
+                    return fail()
+                }
+                cube.Title = title[1:len(title) - 1]
+
+            case "LUT_1D_SIZE", "LUT_3D_SIZE":
+                if (len(fields) != 2 || cube.Dimensions != 0) {
+                    return fail()
+                }
+
+                size, err := strconv.Atoi(fields[1])
+                if (err != nil || size < 2 || size > 65536 || (fields[0] == "LUT_3D_SIZE" && size > 256)) {
+                    return fail()
+                }
+
+                cube.Size = size
+                if (fields[0] == "LUT_1D_SIZE") {
+                    cube.Dimensions = 1
+                    cube.Data = make([]RGB, 0, size)
+                } else {
+                    cube.Dimensions = 3
+                    cube.Data = make([]RGB, 0, size * size * size)
+                }
+
+            case "DOMAIN_MIN", "DOMAIN_MAX":
+                v, err := parseCubeRGB(fields[1:])
+                if err != nil {
+                    return fail()
+                }
+
+                if (fields[0] == "DOMAIN_MIN") {
+                    cube.DomainMin = v
+                } else {
+                    cube.DomainMax = v
+                }
+
+            case "LUT_1D_INPUT_RANGE", "LUT_3D_INPUT_RANGE": // Resolve's variant of DOMAIN_MIN/DOMAIN_MAX
+                if (len(fields) != 3) {
+                    return fail()
+                }
+
+                min, err1 := strconv.ParseFloat(fields[1], 64)
+                max, err2 := strconv.ParseFloat(fields[2], 64)
+                if (err1 != nil || err2 != nil) {
+                    return fail()
+                }
+                cube.DomainMin, cube.DomainMax = RGB{min, min, min}, RGB{max, max, max}
+
+            default:
+                v, err := parseCubeRGB(fields)
+                if err != nil {
+                    if (text[0] >= 'A' && text[0] <= 'Z') { // unknown keywords are ignored
+                        continue
+                    }
+                    return fail()
+                }
+
+                if (cube.Dimensions == 0 || len(cube.Data) == cap(cube.Data)) {
+                    return fail()
+                }
+                cube.Data = append(cube.Data, v)
+        }
+    }
+
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    if (cube.Dimensions == 0 || len(cube.Data) != cap(cube.Data)) {
+        return fail()
+    }
+
+    return cube, nil
+}
+
+// Helpers for lattice positions
+func (cube *CubeLUT) step() RGB {
+    n := float64(cube.Size - 1)
+    min, max := cube.DomainMin, cube.DomainMax
+
+    return RGB{(max.R - min.R) / n, (max.G - min.G) / n, (max.B - min.B) / n}
+}
+
+func (cube *CubeLUT) point(r, g, b int, step RGB) RGB {
+    min := cube.DomainMin
+    return RGB{min.R + float64(r) * step.R, min.G + float64(g) * step.G, min.B + float64(b) * step.B}
+}
+
+// Fractional lattice index for an input value, clamped to the lattice
+func (cube *CubeLUT) index(in, min, step float64) float64 {
+    return Clamp{0, float64(cube.Size - 1)}.GetSingle()((in - min) / step)
+}
+
+// Linear interpolation in a 1D LUT, for the given channel
+func lerp1D(data []RGB, pos float64, channel int) float64 {
+    i := cellIndex(pos, len(data) - 1)
+    t := pos - float64(i)
+
+    return channelOf(data[i], channel) * (1 - t) + channelOf(data[i + 1], channel) * t
+}
+
+func channelOf(v RGB, channel int) float64 {
+    switch channel {
+        case 0: return v.R
+        case 1: return v.G
+    }
+    return v.B
+}
+
+// Number formatting
+func formatCubeRGB(v RGB) string {
+    return fmt.Sprintf("%.6f %.6f %.6f", v.R, v.G, v.B)
+}
+
+func parseCubeRGB(fields []string) (RGB, error) {
+    var v [3]float64
+
+    if (len(fields) != 3) {
+        return RGB{}, ErrInvalidCube
+    }
+
+    for i, f := range fields {
+        x, err := strconv.ParseFloat(f, 64)
+        if err != nil {
+            return RGB{}, err
+        }
+        v[i] = x
+    }
+
+    return RGB{v[0], v[1], v[2]}, nil
+}
@@ -0,0 +1,102 @@
+package colorplus
+
+import (
+    "bytes"
+    "errors"
+    "strings"
+    "testing"
+)
+
+const testCube = `# Created by hand
+TITLE "Invert"
+
+LUT_3D_SIZE 2
+1 1 1
+0 1 1
+1 0 1
+0 0 1
+1 1 0
+0 1 0
+1 0 0
+0 0 0
+`
+
+func TestCube(t *testing.T) {
+    cube, err := ReadCubeLUT(strings.NewReader(testCube))
+    if err != nil {
+        t.Fatalf("ReadCubeLUT(testCube) = %v", err)
+    }
+
+    if (cube.Title != "Invert" || cube.Dimensions != 3 || cube.Size != 2 || cube.Comments[0] != "Created by hand") {
+        t.Errorf("ReadCubeLUT(testCube) = %v", cube)
+    }
+
+    FuzzyAssertTriple(RGB{0.2, 0.4, 0.8}, cube.GetTriple()(RGB{0.2, 0.4, 0.8}), RGB{0.8, 0.6, 0.2}, allow, "testCube", t)
+
+    // Titles are taken as is, without escapes
+    title := `C:\LUTs\"Night" look`
+    cube.Title = title
+    var out bytes.Buffer
+    cube.WriteTo(&out)
+    if line := strings.SplitN(out.String(), "\n", 3)[1]; line != "TITLE \"" + title + "\"" {
+        t.Errorf("cube.WriteTo() wrote %q", line)
+    }
+    if res, err := ReadCubeLUT(&out); err != nil {
+        t.Errorf("ReadCubeLUT(cube.WriteTo()) = %v", err)
+    } else if (res.Title != title) {
+        t.Errorf("ReadCubeLUT(cube.WriteTo()).Title = %q, want %q", res.Title, title)
+    }
+
+    // Round trip through a file, for both kinds of LUT
+    chain := Chain(SpacesRGB.GetDecoder(), XYZSpace(SpaceAdobeRGB98).GetEncoder(), SRGBCurve.GetEncoder(), Clamp{0, 1})
+
+    for _, dim := range []int{1, 3} {
+        cube = MakeCubeLUT(dim, 17)
+        cube.Title = "sRGB to Adobe RGB"
+        if err := cube.Assign(chain); err != nil {
+            t.Fatalf("cube.Assign(chain) = %v", err)
+        }
+
+        var buf bytes.Buffer
+        cube.WriteTo(&buf)
+
+        res, err := ReadCubeLUT(&buf)
+        if err != nil {
+            t.Fatalf("ReadCubeLUT(cube.WriteTo()) = %v", err)
+        }
+
+        for i, v := range cube.Data {
+            FuzzyAssertTriple(i, res.Data[i], v, 0.000001, "ReadCubeLUT(cube.WriteTo())", t)
+        }
+    }
+
+    // Lattice points are reproduced exactly
+    FuzzyAssertTriple(RGB{1, 0.5, 0}, cube.GetTriple()(RGB{1, 0.5, 0}), chain.GetTriple()(RGB{1, 0.5, 0}), allow, "cube.GetTriple()", t)
+
+    // Conversion to and from LUT3D
+    lut := cube.ToLUT3D([]int32{4, 4, 4}, 16, RangeFull, RangeFull, SpacesRGB, SpaceAdobeRGB98)
+    back, err := CubeFromLUT3D(lut)
+    if err != nil {
+        t.Fatalf("CubeFromLUT3D() = %v", err)
+    }
+
+    FuzzyAssertTriple(RGB{1, 0, 1}, back.GetTriple()(RGB{1, 0, 1}), cube.GetTriple()(RGB{1, 0, 1}), 0.0001, "CubeFromLUT3D(cube.ToLUT3D())", t)
+
+    // Broken files
+    for _, f := range []string{"LUT_3D_SIZE 2\n0 0 0\n", "LUT_1D_SIZE 2\n0 0 0\n1 1\n", "0 0 0\n", "TITLE Unquoted\n", "TITLE \"\n"} {
+        if _, err := ReadCubeLUT(strings.NewReader(f)); !errors.Is(err, ErrInvalidCube) {
+            t.Errorf("ReadCubeLUT(%q) = %v, want %v", f, err, ErrInvalidCube)
+        }
+    }
+
+    // Invalid sizes and filters
+    for _, size := range [][2]int{{1, 1}, {3, 0}, {2, 17}, {3, 257}} {
+        if _, err := MakeCubeLUTChecked(size[0], size[1]); err != ErrInvalidCubeSize {
+            t.Errorf("MakeCubeLUTChecked(%v, %v) = %v, want %v", size[0], size[1], err, ErrInvalidCubeSize)
+        }
+    }
+
+    if err := MakeCubeLUT(3, 2).Assign(Chain(SpacesRGB.GetDecoder(), XYZtoYxy)); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("cube.Assign(returns Yxy) = %v, want %v", err, ErrUnsupportedType)
+    }
+}
@@ -18,6 +18,24 @@ type sRGBCurve byte
 // L* mode determines whether to follow the intent of the CIE Standard, or its written value, represented here as bool
 type LStarCurve bool
 
+// SMPTE ST 2084 (PQ), linear light is normalized such that 1.0 = Peak in cd/m², a Peak of 0 means the full 10000 cd/m²
+type PQCurve struct {
+    Peak float64
+}
+
+// ARIB STD-B67 (HLG), linear light is scene-referred unless SystemGamma is set, in which case the decoder includes the
+// OOTF (and the encoder its inverse), applied per channel. For the luminance-based OOTF, use HLGOOTF on RGB instead
+type HLGCurve struct {
+    SystemGamma float64
+}
+
+// ITU-R BT.1886 EOTF for a display with white and black luminance Lw and Lb in cd/m² (Lw must be positive). Linear light
+// is normalized such that 1.0 = Lw. If Annex1 is set, the two-part EOTF suggested for closer CRT matching is used instead
+type BT1886Curve struct {
+    Lw, Lb float64
+    Annex1 bool
+}
+
 const (
     LStarActual LStarCurve = true
     LStarIntent LStarCurve = false
@@ -96,3 +114,240 @@ func (ls LStarCurve) params() (E, K float64) {
 
     return
 }
+
+// PQ is an absolute curve covering 0-10000 cd/m²
+const (
+    pqM1 = 2610.0 / 16384.0
+    pqM2 = 2523.0 / 4096.0 * 128.0
+    pqC1 = 3424.0 / 4096.0
+    pqC2 = 2413.0 / 4096.0 * 32.0
+    pqC3 = 2392.0 / 4096.0 * 32.0
+    pqMax = 10000.0
+)
+
+func (pq PQCurve) GetEncoder() FilterSingle {
+    scale := pq.scale()
+
+    return func(in float64) float64 {
+        if (in > 0) {
+            Y := math.Pow(in * scale, pqM1)
+            return math.Pow((pqC1 + pqC2 * Y) / (1 + pqC3 * Y), pqM2)
+        }
+        return 0
+    }
+}
+
+func (pq PQCurve) GetDecoder() FilterSingle {
+    scale := pq.scale()
+
+    return func(in float64) float64 {
+        if (in > 0) {
+            E := math.Pow(in, 1 / pqM2)
+            return math.Pow(math.Max(E - pqC1, 0) / (pqC2 - pqC3 * E), 1 / pqM1) / scale
+        }
+        return 0
+    }
+}
+
+func (pq PQCurve) scale() float64 {
+    if (pq.Peak == 0) {
+        return 1
+    }
+    return pq.Peak / pqMax
+}
+
+// HLG is a two-part curve with a square root segment for blacks and a logarithmic segment for highlights
+const (
+    hlgA = 0.17883277
+    hlgB = 1 - 4 * hlgA
+)
+
+var hlgC = 0.5 - hlgA * math.Log(4 * hlgA)
+
+func (hlg HLGCurve) GetEncoder() FilterSingle {
+    gp := 1.0
+    if (hlg.SystemGamma != 0) {
+        gp = 1 / hlg.SystemGamma
+    }
+
+    return func(in float64) float64 {
+        if (in <= 0) {
+            return 0
+        }
+
+        in = math.Pow(in, gp) // inverse OOTF
+        if (in > 1.0 / 12.0) {
+            return hlgA * math.Log(12 * in - hlgB) + hlgC
+        }
+        return math.Sqrt(3 * in)
+    }
+}
+
+func (hlg HLGCurve) GetDecoder() FilterSingle {
+    g := 1.0
+    if (hlg.SystemGamma != 0) {
+        g = hlg.SystemGamma
+    }
+
+    return func(in float64) float64 {
+        if (in <= 0) {
+            return 0
+        }
+
+        if (in > 0.5) {
+            in = (math.Exp((in - hlgC) / hlgA) + hlgB) / 12
+        } else {
+            in = in * in / 3
+        }
+        return math.Pow(in, g) // OOTF
+    }
+}
+
+// The HLG system gamma for a display with nominal peak luminance Lw in cd/m²
+func HLGSystemGamma(Lw float64) float64 {
+    return 1.2 + 0.42 * math.Log10(Lw / 1000)
+}
+
+// The HLG OOTF as specified by ITU-R BT.2100, operating on BT.2020 RGB. The decoder maps scene-linear to display-linear
+// light (normalized so that 1.0 = nominal peak luminance), the encoder is its inverse. A SystemGamma of 0 means the
+// reference value of 1.2 for a 1000 cd/m² display
+type HLGOOTF struct {
+    SystemGamma float64
+}
+
+func (o HLGOOTF) gamma() float64 {
+    if (o.SystemGamma == 0) {
+        return 1.2
+    }
+    return o.SystemGamma
+}
+
+func (o HLGOOTF) GetEncoder() FilterTriple {
+    g := o.gamma()
+    return o.scaler("HLGOOTF.GetEncoder", (1 - g) / g)
+}
+
+func (o HLGOOTF) GetDecoder() FilterTriple {
+    return o.scaler("HLGOOTF.GetDecoder", o.gamma() - 1)
+}
+
+// Scales all channels by a power of the BT.2020 luminance
+func (o HLGOOTF) scaler(name string, exp float64) FilterTriple {
+    return func(in Triple) Triple {
+        switch v := in.(type) {
+            case RGB:
+                Y := 0.2627 * v.R + 0.6780 * v.G + 0.0593 * v.B
+                if (Y <= 0) {
+                    return RGB{0, 0, 0}
+                }
+
+                s := math.Pow(Y, exp)
+                return RGB{v.R * s, v.G * s, v.B * s}
+            default: panic(unsupported(name, in))
+        }
+    }
+}
+
+// BT.1886 is a power curve offset so that an input of 0 produces the display's black level
+const (
+    bt1886Gamma = 2.4
+    bt1886Vc = 0.35 // crossover point and exponents for the two-part curve
+    bt1886A1 = 2.6
+    bt1886A2 = 3.0
+)
+
+func (bc BT1886Curve) GetEncoder() FilterSingle {
+    k, b := bc.params()
+
+    if (bc.Annex1) {
+        lc := k * math.Pow(bt1886Vc + b, bt1886A1)
+        kl := k * math.Pow(bt1886Vc + b, bt1886A1 - bt1886A2)
+
+        return func(in float64) float64 {
+            switch {
+                case (in <= 0): return -b
+                case (in < lc): return math.Pow(in / kl, 1 / bt1886A2) - b
+            }
+            return math.Pow(in / k, 1 / bt1886A1) - b
+        }
+    }
+
+    return func(in float64) float64 {
+        if (in > 0) {
+            return math.Pow(in / k, 1 / bt1886Gamma) - b
+        }
+        return -b
+    }
+}
+
+func (bc BT1886Curve) GetDecoder() FilterSingle {
+    k, b := bc.params()
+
+    if (bc.Annex1) {
+        kl := k * math.Pow(bt1886Vc + b, bt1886A1 - bt1886A2)
+
+        return func(in float64) float64 {
+            switch {
+                case (in + b <= 0): return 0
+                case (in < bt1886Vc): return kl * math.Pow(in + b, bt1886A2)
+            }
+            return k * math.Pow(in + b, bt1886A1)
+        }
+    }
+
+    return func(in float64) float64 {
+        if (in + b > 0) {
+            return k * math.Pow(in + b, bt1886Gamma)
+        }
+        return 0
+    }
+}
+
+// Returns the gain k and the black lift b, with k already normalized to Lw
+func (bc BT1886Curve) params() (k, b float64) {
+    if (!bc.Annex1) {
+        w, l := math.Pow(bc.Lw, 1 / bt1886Gamma), math.Pow(bc.Lb, 1 / bt1886Gamma)
+        b = l / (w - l)
+
+        return math.Pow(w - l, bt1886Gamma) / bc.Lw, b
+    }
+
+    // There is no closed form for b here, but the black level is monotonic in b so we can bisect
+    black := func(b float64) float64 {
+        return math.Pow(bt1886Vc + b, bt1886A1 - bt1886A2) * math.Pow(b, bt1886A2) / math.Pow(1 + b, bt1886A1)
+    }
+
+    lo, hi, want := 0.0, 1.0, bc.Lb / bc.Lw
+    for black(hi) < want {
+        hi *= 2
+    }
+    for i := 0; i < 100; i++ {
+        b = (lo + hi) / 2
+        if (black(b) < want) {
+            lo = b
+        } else {
+            hi = b
+        }
+    }
+
+    return 1 / math.Pow(1 + b, bt1886A1), b
+}
+
+// The companding function used by L*a*b*, this is the same as the L* curve before scaling
+func (ls LStarCurve) labF(in float64) float64 {
+    E, K := ls.params()
+
+    if (in > E) {
+        return math.Cbrt(in)
+    }
+    return (K * in + 16) / 116
+}
+
+func (ls LStarCurve) labFInverse(in float64) float64 {
+    E, K := ls.params()
+
+    if (in * in * in > E) {
+        return in * in * in
+    }
+    return (116 * in - 16) / K
+}
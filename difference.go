@@ -0,0 +1,192 @@
+package colorplus
+
+import "math"
+
+// Color difference metrics. Colors of any type are converted to CIELab (or ICtCp for ΔE ITP) by the converter, so the
+// reference white of the converter applies. Unlike Lab values in this package, differences are on the usual scale
+// where 1.0 is about a just noticeable difference
+
+// Weights for CIE94, K1 and K2 scale the chroma and hue terms by the chroma of the reference color
+type CIE94Weights struct {
+    KL, KC, KH, K1, K2 float64
+}
+
+var (
+    CIE94GraphicArts = CIE94Weights{1, 1, 1, 0.045, 0.015}
+    CIE94Textiles = CIE94Weights{2, 1, 1, 0.048, 0.014}
+)
+
+// Parametric weights of CIEDE2000, all 1 for the reference conditions
+type CIEDE2000Weights struct {
+    KL, KC, KH float64
+}
+
+var CIEDE2000Reference = CIEDE2000Weights{1, 1, 1}
+
+// Shorthands using the default converter
+func DeltaE76(a, b Triple) float64 {
+    return DefaultConverter.DeltaE76(a, b)
+}
+
+func DeltaE94(ref, sample Triple, w CIE94Weights) float64 {
+    return DefaultConverter.DeltaE94(ref, sample, w)
+}
+
+func DeltaE2000(a, b Triple, w CIEDE2000Weights) float64 {
+    return DefaultConverter.DeltaE2000(a, b, w)
+}
+
+func DeltaECMC(ref, sample Triple, l, c float64) float64 {
+    return DefaultConverter.DeltaECMC(ref, sample, l, c)
+}
+
+func DeltaEITP(a, b Triple, Lw float64) float64 {
+    return DefaultConverter.DeltaEITP(a, b, Lw)
+}
+
+// CIE 1976, the euclidean distance in CIELab
+func (c Converter) DeltaE76(a, b Triple) float64 {
+    x, y := c.labPair(a, b)
+    return math.Sqrt(sq(x.L - y.L) + sq(x.A - y.A) + sq(x.B - y.B))
+}
+
+// CIE 1994, not symmetric: the chroma of ref determines the weighting
+func (c Converter) DeltaE94(ref, sample Triple, w CIE94Weights) float64 {
+    x, y := c.labPair(ref, sample)
+
+    C1, C2 := math.Hypot(x.A, x.B), math.Hypot(y.A, y.B)
+    dL, dC := x.L - y.L, C1 - C2
+    dH2 := sq(x.A - y.A) + sq(x.B - y.B) - dC * dC
+
+    SC, SH := 1 + w.K1 * C1, 1 + w.K2 * C1
+
+    return math.Sqrt(sq(dL / w.KL) + sq(dC / (w.KC * SC)) + math.Max(0, dH2) / sq(w.KH * SH))
+}
+
+// CIEDE2000
+func (c Converter) DeltaE2000(a, b Triple, w CIEDE2000Weights) float64 {
+    x, y := c.labPair(a, b)
+
+    // Rescale a* such that neutrals are less affected by chroma differences
+    Cm := (math.Hypot(x.A, x.B) + math.Hypot(y.A, y.B)) / 2
+    G := 0.5 * (1 - math.Sqrt(math.Pow(Cm, 7) / (math.Pow(Cm, 7) + math.Pow(25, 7))))
+
+    a1, a2 := x.A * (1 + G), y.A * (1 + G)
+    C1, C2 := math.Hypot(a1, x.B), math.Hypot(a2, y.B)
+    h1, h2 := hueDegrees(a1, x.B), hueDegrees(a2, y.B)
+
+    // Hue difference, along the shorter way around
+    dh := 0.0
+    if (C1 * C2 != 0) {
+        dh = h2 - h1
+        if (dh > 180) {
+            dh -= 360
+        } else if (dh < -180) {
+            dh += 360
+        }
+    }
+
+    dL, dC := y.L - x.L, C2 - C1
+    dH := 2 * math.Sqrt(C1 * C2) * math.Sin(radians(dh / 2))
+
+    // Means
+    Lm, Cm := (x.L + y.L) / 2, (C1 + C2) / 2
+    hm := h1 + h2
+    if (C1 * C2 != 0) {
+        if (math.Abs(h1 - h2) > 180) {
+            if (hm < 360) {
+                hm += 360
+            } else {
+                hm -= 360
+            }
+        }
+        hm /= 2
+    }
+
+    T := 1 - 0.17 * math.Cos(radians(hm - 30)) + 0.24 * math.Cos(radians(2 * hm)) +
+        0.32 * math.Cos(radians(3 * hm + 6)) - 0.20 * math.Cos(radians(4 * hm - 63))
+
+    SL := 1 + 0.015 * sq(Lm - 50) / math.Sqrt(20 + sq(Lm - 50))
+    SC := 1 + 0.045 * Cm
+    SH := 1 + 0.015 * Cm * T
+
+    // Rotation term for blues
+    dTheta := 30 * math.Exp(-sq((hm - 275) / 25))
+    RT := -2 * math.Sqrt(math.Pow(Cm, 7) / (math.Pow(Cm, 7) + math.Pow(25, 7))) * math.Sin(radians(2 * dTheta))
+
+    l, c2, h := dL / (w.KL * SL), dC / (w.KC * SC), dH / (w.KH * SH)
+    return math.Sqrt(l * l + c2 * c2 + h * h + RT * c2 * h)
+}
+
+// CMC l:c (usually 2:1 for acceptability and 1:1 for perceptibility), not symmetric like CIE94
+func (c Converter) DeltaECMC(ref, sample Triple, l, cw float64) float64 {
+    x, y := c.labPair(ref, sample)
+
+    C1, C2 := math.Hypot(x.A, x.B), math.Hypot(y.A, y.B)
+    dL, dC := x.L - y.L, C1 - C2
+    dH2 := sq(x.A - y.A) + sq(x.B - y.B) - dC * dC
+
+    SL := 0.040975 * x.L / (1 + 0.01765 * x.L)
+    if (x.L < 16) {
+        SL = 0.511
+    }
+    SC := 0.0638 * C1 / (1 + 0.0131 * C1) + 0.638
+
+    h1 := hueDegrees(x.A, x.B)
+    T := 0.36 + math.Abs(0.4 * math.Cos(radians(h1 + 35)))
+    if (164 <= h1 && h1 <= 345) {
+        T = 0.56 + math.Abs(0.2 * math.Cos(radians(h1 + 168)))
+    }
+
+    F := math.Sqrt(math.Pow(C1, 4) / (math.Pow(C1, 4) + 1900))
+    SH := SC * (F * T + 1 - F)
+
+    return math.Sqrt(sq(dL / (l * SL)) + sq(dC / (cw * SC)) + math.Max(0, dH2) / sq(SH))
+}
+
+// ITU-R BT.2124, for HDR content. Lw is the luminance of white (Y = 1) in cd/m²
+func (c Converter) DeltaEITP(a, b Triple, Lw float64) float64 {
+    I1, T1, P1 := c.ictcp(a, Lw)
+    I2, T2, P2 := c.ictcp(b, Lw)
+
+    return 720 * math.Sqrt(sq(I1 - I2) + sq(T1 - T2) + sq(P1 - P2))
+}
+
+// Helpers, Lab is returned on the usual 0-100 scale
+func (c Converter) labPair(a, b Triple) (Lab, Lab) {
+    x, y := c.Convert(a, Lab{}).(Lab), c.Convert(b, Lab{}).(Lab)
+    return Lab{x.L * 100, x.A * 100, x.B * 100}, Lab{y.L * 100, y.A * 100, y.B * 100}
+}
+
+// I, T and P, where T is half of Ct as in BT.2124
+func (c Converter) ictcp(in Triple, Lw float64) (I, T, P float64) {
+    x := c.Convert(in, XYZ{}).(XYZ)
+    rgb := SpaceBT2020.XYZToRGB().Mul1x3(Matrix1x3{x.X, x.Y, x.Z})
+
+    pq := PQCurve{Lw}.GetEncoder()
+    L := pq((1688 * rgb.M1 + 2146 * rgb.M2 + 262 * rgb.M3) / 4096)
+    M := pq((683 * rgb.M1 + 2951 * rgb.M2 + 462 * rgb.M3) / 4096)
+    S := pq((99 * rgb.M1 + 309 * rgb.M2 + 3688 * rgb.M3) / 4096)
+
+    return (L + M) / 2, (6610 * L - 13613 * M + 7003 * S) / 8192, (17933 * L - 17390 * M - 543 * S) / 4096
+}
+
+func hueDegrees(a, b float64) float64 {
+    if (a == 0 && b == 0) {
+        return 0
+    }
+
+    h := math.Atan2(b, a) * 180 / math.Pi
+    if (h < 0) {
+        h += 360
+    }
+    return h
+}
+
+func radians(deg float64) float64 {
+    return deg * math.Pi / 180
+}
+
+func sq(x float64) float64 {
+    return x * x
+}
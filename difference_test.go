@@ -0,0 +1,49 @@
+package colorplus
+
+import "testing"
+
+// Lab on the usual 0-100 scale
+func lab100(L, a, b float64) Lab {
+    return Lab{L / 100, a / 100, b / 100}
+}
+
+func TestDifference(t *testing.T) {
+    // Test data from Sharma, Wu & Dalal (2005)
+    tests := []struct {
+        a, b Lab
+        want float64
+    }{
+        {lab100(50, 2.6772, -79.7751), lab100(50, 0, -82.7485), 2.0425},
+        {lab100(50, 0, 0), lab100(50, -1, 2), 2.3669},
+        {lab100(50, 2.5, 0), lab100(73, 25, -18), 27.1492},
+        {lab100(50, 2.5, 0), lab100(50, 3.1736, 0.5854), 1},
+        {lab100(60.2574, -34.0099, 36.2677), lab100(60.4626, -34.1751, 39.4387), 1.2644},
+        {lab100(22.7233, 20.0904, -46.694), lab100(23.0331, 14.973, -42.5619), 2.0373},
+        {lab100(2.0776, 0.0795, -1.135), lab100(0.9033, -0.0636, -0.5514), 0.9082},
+    }
+
+    for _, v := range tests {
+        FuzzyAssertSingle(v.a, DeltaE2000(v.a, v.b, CIEDE2000Reference), v.want, 1e-4, "DeltaE2000", t)
+        FuzzyAssertSingle(v.b, DeltaE2000(v.b, v.a, CIEDE2000Reference), v.want, 1e-4, "DeltaE2000", t)
+    }
+
+    a, b := lab100(50, 10, 10), lab100(53, 14, 7)
+    FuzzyAssertSingle(a, DeltaE76(a, b), 5.830952, allow, "DeltaE76", t)
+    FuzzyAssertSingle(a, DeltaE94(a, b, CIE94GraphicArts), 5.031364, allow, "DeltaE94", t)
+    FuzzyAssertSingle(a, DeltaE94(a, lab100(56, 10, 10), CIE94Textiles), 3, allow, "DeltaE94", t)
+    FuzzyAssertSingle(a, DeltaECMC(a, b, 2, 1), 7.893961, allow, "DeltaECMC", t)
+
+    // Types other than Lab are converted first
+    FuzzyAssertSingle(RGB{1, 1, 1}, DeltaE76(RGB{1, 1, 1}, PointD65), 0, allow, "DeltaE76", t)
+    FuzzyAssertSingle(RGB{1, 1, 1}, DeltaEITP(RGB{1, 1, 1}, RGB{1, 1, 1}, 100), 0, allow, "DeltaEITP", t)
+
+    // ΔE ITP of the ICtCp pair {0.488547, -0.047394, 0.074754} and {0.489920, -0.045675, 0.073613} (the example of
+    // colour-science), given as linear BT.2020 RGB where 1.0 = 10000 cd/m²
+    bt2020 := func(r, g, b float64) XYZ {
+        x := SpaceBT2020.RGBToXYZ().Mul1x3(Matrix1x3{r, g, b})
+        return XYZ{x.M1, x.M2, x.M3}
+    }
+
+    c, d := bt2020(0.0119086321, 0.0070708489, 0.0045113099), bt2020(0.0120192580, 0.0071828079, 0.0046554328)
+    FuzzyAssertSingle(c, DeltaEITP(c, d, 10000), 1.426574, 1e-4, "DeltaEITP", t)
+}
@@ -4,7 +4,6 @@ package main
 
 import (
     "colorplus"
-    "image"
     "image/png"
     "os"
 )
@@ -22,16 +21,19 @@ func main() {
 
     defer io.Close()
 
-    // Decode this to an NRGBA
+    // Decode the image, any image type will do
     n, err := png.Decode(io)
-    image := (*image.RGBA)(n.(*image.NRGBA)) // we need it as RGBA, not NRGBA
 
     if err != nil {
         panic(err)
     }
 
-    // Apply our chain to the image (argument must be *image.RGBA)
-    colorplus.ApplyToImage(image, chain)
+    // Apply our chain to a copy of the image
+    image, err := colorplus.FilterImage(n, chain)
+
+    if err != nil {
+        panic(err)
+    }
 
     // Save the result
     o, err := os.Create("output.png")
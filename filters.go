@@ -1,5 +1,11 @@
 package colorplus
 
+import (
+    "errors"
+    "fmt"
+    "reflect"
+)
+
 // Filters are just functions which transform colors
 type FilterTriple func(Triple) Triple
 type FilterSingle func(float64) float64
@@ -12,21 +18,159 @@ type FilterSingleProvider interface {
     GetSingle() FilterSingle
 }
 
+// Checked providers report invalid configurations and unsupported input types while the filter is built. The
+// prototype in is any value of the input type, out is a value of the output type
+type CheckedTripleProvider interface {
+    FilterTripleProvider
+    GetTripleChecked(in Triple) (f FilterTriple, out Triple, err error)
+}
+
+// Typed providers declare the color type they expect as input (as a prototype value), converting chains convert to it
+type TypedTripleProvider interface {
+    FilterTripleProvider
+    InputType() Triple
+}
+
+// A filter which reports errors instead of panicking
+type CheckedFilterTriple func(Triple) (Triple, error)
+
+var (
+    ErrUnsupportedType = errors.New("Unsupported color type")
+    ErrInvalidProvider = errors.New("Not a valid filter provider")
+    ErrOutOfRange = errors.New("Value out of range")
+)
+
+// Linear filters apply an affine transform (matrix plus offset), which lets Chain fuse adjacent ones into one. The
+// input and output prototypes determine the color types, if nil any type is accepted and the output has the same type
+type LinearFilter struct {
+    m Matrix3x3
+    offset Matrix1x3
+    in, out Triple
+}
+
+// Providers of linear filters
+type linearProvider interface {
+    linear() LinearFilter
+}
+
 type CodingProvider interface { // Like curve provider but for triples
     GetEncoder() FilterTriple
     GetDecoder() FilterTriple
 }
 
 // Two filters chained together
-type filterTripleChain []interface{} // can be triple or single
+type filterTripleChain struct {
+    list []interface{} // can be triple or single
+    conv Converter // parameters of the inserted conversions
+}
 type filterSingleChain []FilterSingleProvider
 type filterMultiplex struct {
     a, b, c FilterSingleProvider
 }
 
-// Function to concatenate filters together
+// Linear filter implementation
+func (lf LinearFilter) linear() LinearFilter {
+    return lf
+}
+
+func (lf LinearFilter) InputType() Triple {
+    return lf.in
+}
+
+// Without a prototype the type of each input is checked, like other filters on XYZ Yxy is accepted as well
+func (lf LinearFilter) GetTriple() FilterTriple {
+    switch lf.in.(type) {
+        case nil: return lf.apply
+        case XYZ:
+            return func(in Triple) Triple {
+                switch v := in.(type) {
+                    case XYZ: return lf.apply(v)
+                    case Yxy: return lf.apply(v.ToXYZ())
+                }
+                panic(unsupported("LinearFilter", in))
+            }
+        case RGB:
+            return func(in Triple) Triple {
+                if _, ok := in.(RGB); !ok {
+                    panic(unsupported("LinearFilter", in))
+                }
+                return lf.apply(in)
+            }
+        default: panic(unsupported("LinearFilter", lf.in))
+    }
+}
+
+// Built for inputs like in, the resulting filter does not check types again
+func (lf LinearFilter) GetTripleChecked(in Triple) (FilterTriple, Triple, error) {
+    if (in == nil) {
+        return lf.GetTriple(), lf.out, nil
+    }
+
+    if err := accepts("LinearFilter", in, inputTypes(lf.in)...); err != nil {
+        return nil, nil, err
+    }
+
+    out := lf.out
+    if (out == nil) {
+        out = in
+    }
+
+    if _, ok := in.(Yxy); ok && lf.in != nil {
+        return func(in Triple) Triple {
+            return lf.apply(in.(Yxy).ToXYZ())
+        }, out, nil
+    }
+
+    return lf.apply, out, nil
+}
+
+// The transform without type checks
+func (lf LinearFilter) apply(in Triple) Triple {
+    out := lf.out
+    if (out == nil) {
+        out = in
+    }
+
+    a, b, c := in.Get()
+    res := lf.m.Mul1x3(Matrix1x3{a, b, c})
+
+    return out.Make(res.M1 + lf.offset.M1, res.M2 + lf.offset.M2, res.M3 + lf.offset.M3)
+}
+
+// Combine two linear filters into one, applying lf first and then next. The result takes the input type of lf
+func (lf LinearFilter) then(next LinearFilter) LinearFilter {
+    o := next.m.Mul1x3(lf.offset)
+    res := LinearFilter{next.m.Mul3x3(lf.m), Matrix1x3{o.M1 + next.offset.M1, o.M2 + next.offset.M2, o.M3 + next.offset.M3}, lf.in, next.out}
+
+    if (next.out == nil) {
+        res.out = lf.out
+    }
+    return res
+}
+
+// Function to concatenate filters together. Where a filter declares an input type (see TypedTripleProvider) other
+// than the type of its input, a conversion is inserted using the parameters DefaultConverter has at this point
 func Chain(list ...interface{}) (FilterTripleProvider) {
-    return filterTripleChain(list)
+    return DefaultConverter.Chain(list...)
+}
+
+// Chain with automatic conversions using the parameters of c
+func (c Converter) Chain(list ...interface{}) (FilterTripleProvider) {
+    return filterTripleChain{list, c}
+}
+
+// Declare the input type of a filter, for use in Chain
+func Typed(f FilterTripleProvider, in Triple) TypedTripleProvider {
+    return typedFilter{f, in}
+}
+
+type typedFilter struct {
+    FilterTripleProvider
+    in Triple
+}
+
+func (tf typedFilter) InputType() Triple {
+    return tf.in
 }
 
 func ChainSingle(list ...FilterSingleProvider) (FilterSingleProvider) {
@@ -38,24 +182,224 @@ func Multiplex(a, b, c FilterSingleProvider) (FilterTripleProvider) {
     return filterMultiplex{a, b, c}
 }
 
-// Filter provider implementation for filter chains
+// Build a checked filter from any filter provider accepted by Chain, for inputs of the same type as in. All errors
+// that would otherwise cause a panic on the first call are returned here instead. The resulting filter rejects inputs
+// of any other type, and returns an error instead of panicking for invalid inputs
+func BuildChecked(provider interface{}, in Triple) (CheckedFilterTriple, error) {
+    f, _, err := getTripleChecked(provider, in)
+    if err != nil {
+        return nil, err
+    }
+
+    want := reflect.TypeOf(in)
+
+    return func(v Triple) (res Triple, err error) {
+        if (reflect.TypeOf(v) != want) {
+            return nil, fmt.Errorf("%w: got %T, want %v", ErrUnsupportedType, v, want)
+        }
+
+        defer recoverError(&err)
+        return f(v), nil
+    }, nil
+}
+
+// Build a single filter provider for the given input prototype, without calling the filter. Types are checked
+// against what the provider declares, if it declares nothing any input is accepted and out is nil (unknown). A nil
+// prototype is unknown as well
+func getTripleChecked(provider interface{}, in Triple) (f FilterTriple, out Triple, err error) {
+    defer recoverError(&err)
+
+    switch x := provider.(type) {
+        case CheckedTripleProvider: return x.GetTripleChecked(in)
+        case linearProvider: return x.linear().GetTripleChecked(in)
+        case TypedTripleProvider:
+            if err := accepts(fmt.Sprintf("%T", x), in, inputTypes(x.InputType())...); err != nil {
+                return nil, nil, err
+            }
+            return x.GetTriple(), nil, nil
+        case FilterSingleProvider: return x.GetSingle().GetTriple(), in, nil // keeps the type
+        case FilterTripleProvider: return x.GetTriple(), nil, nil
+        default: return nil, nil, fmt.Errorf("%w: %T", ErrInvalidProvider, provider)
+    }
+}
+
+// The declared input type of a provider, nil if it has none
+func inputTypeOf(provider interface{}) Triple {
+    if tf, ok := provider.(TypedTripleProvider); ok {
+        return tf.InputType()
+    }
+    return nil
+}
+
+// The types accepted by a filter declaring the input type t, filters on XYZ take Yxy as well
+func inputTypes(t Triple) []Triple {
+    if _, ok := t.(XYZ); ok {
+        return []Triple{t, Yxy{}}
+    }
+    return []Triple{t}
+}
+
+// Checks the input prototype against the accepted types. Unknown inputs (nil) pass, as does anything if no types are
+// declared
+func accepts(name string, in Triple, types ...Triple) error {
+    if (in == nil || len(types) == 0 || types[0] == nil) {
+        return nil
+    }
+
+    for _, t := range types {
+        if (reflect.TypeOf(in) == reflect.TypeOf(t)) {
+            return nil
+        }
+    }
+
+    return unsupported(name, in)
+}
+
+// The error for a filter that does not support its input type
+func unsupported(name string, in Triple) error {
+    return fmt.Errorf("[%s] %w: %T", name, ErrUnsupportedType, in)
+}
+
+// Converts a panic into an error
+func recoverError(err *error) {
+    if r := recover(); r != nil {
+        switch v := r.(type) {
+            case error: *err = v
+            case string: *err = errors.New(v)
+            default: *err = fmt.Errorf("%v", v)
+        }
+    }
+}
+
+// Filter provider implementation for filter chains. The chain is built once, for the input type declared by its first
+// filter. If that is unknown no conversions can be inserted, for this and for inputs of other types use BuildChecked
+// with a prototype instead
 func (ftc filterTripleChain) GetTriple() FilterTriple {
-    cache := make([]FilterTriple, len(ftc))
+    f, _, err := ftc.build(ftc.InputType())
+    if err != nil {
+        panic(err)
+    }
+
+    return f
+}
 
-    for i,f := range ftc {
-        switch x := f.(type) {
-            case FilterTripleProvider: cache[i] = x.GetTriple()
-            case FilterSingleProvider: cache[i] = x.GetSingle().GetTriple()
-            default: panic("Not a valid filter provider!")
+// The declared input type of the first filter, filters which keep the type (like curves) are skipped
+func (ftc filterTripleChain) InputType() Triple {
+    for _, e := range ftc.flatten() {
+        for _, f := range leaves(e.f) {
+            if t := inputTypeOf(f); t != nil {
+                return t
+            }
+            if _, ok := f.(FilterSingleProvider); !ok {
+                return nil
+            }
         }
     }
+    return nil
+}
+
+func (ftc filterTripleChain) GetTripleChecked(in Triple) (FilterTriple, Triple, error) {
+    return ftc.build(in)
+}
+
+// Nested chains are flattened and adjacent linear filters fused into one, if the output type of one is exactly the
+// input type of the next. Chains returned by providers (like Space.Decoder) are split into their parts, so their
+// linear parts are fused too. Each fused filter still checks the type of its inputs, like its first part would
+func (ftc filterTripleChain) build(in Triple) (FilterTriple, Triple, error) {
+    var cache []FilterTriple
+    var lin *LinearFilter // pending linear filters, not yet in cache
+
+    flush := func() {
+        if (lin == nil) {
+            return
+        }
+
+        cache = append(cache, lin.GetTriple())
+        lin = nil
+    }
+
+    for i, e := range ftc.flatten() {
+        conv, to, err := e.conv.conversionFor(e.f, in)
+        if err != nil {
+            return nil, nil, fmt.Errorf("[Chain] Element %d: %w", i, err)
+        }
+
+        if (conv != nil) {
+            flush()
+            cache = append(cache, conv)
+            in = to
+        }
+
+        for _, f := range leaves(e.f) {
+            g, out, err := getTripleChecked(f, in)
+            if err != nil {
+                return nil, nil, fmt.Errorf("[Chain] Element %d: %w", i, err)
+            }
+
+            if lp, ok := f.(linearProvider); ok {
+                l := lp.linear()
+
+                if (lin != nil && reflect.TypeOf(lin.out) == reflect.TypeOf(l.in)) {
+                    l = lin.then(l)
+                } else {
+                    flush()
+                }
+                lin = &l
+            } else {
+                flush()
+                cache = append(cache, g)
+            }
+
+            in = out
+        }
+    }
+
+    flush()
+
+    if (len(cache) == 1) {
+        return cache[0], in, nil
+    }
 
     return func(in Triple) Triple {
         for _,v := range cache {
             in = v(in)
         }
         return in
+    }, in, nil
+}
+
+// Chain elements along with the converter of the chain they came from
+type chainElement struct {
+    f interface{}
+    conv Converter
+}
+
+func (ftc filterTripleChain) flatten() []chainElement {
+    var res []chainElement
+
+    for _, f := range ftc.list {
+        if nested, ok := f.(filterTripleChain); ok {
+            res = append(res, nested.flatten()...)
+        } else {
+            res = append(res, chainElement{f, ftc.conv})
+        }
+    }
+
+    return res
+}
+
+// The parts of a filter, nested chains are split into their filters
+func leaves(f interface{}) []interface{} {
+    nested, ok := f.(filterTripleChain)
+    if !ok {
+        return []interface{}{f}
     }
+
+    var res []interface{}
+    for _, e := range nested.flatten() {
+        res = append(res, leaves(e.f)...)
+    }
+    return res
 }
 
 func (fsc filterSingleChain) GetSingle() FilterSingle {
@@ -83,6 +427,11 @@ func (fm filterMultiplex) GetTriple() FilterTriple {
     }
 }
 
+// Multiplexed filters keep the type of their input
+func (fm filterMultiplex) GetTripleChecked(in Triple) (FilterTriple, Triple, error) {
+    return fm.GetTriple(), in, nil
+}
+
 // Filter provider implementation for basic filters
 func (f FilterTriple) GetTriple() FilterTriple {
     return f
@@ -1,6 +1,10 @@
 package colorplus
 
-import "testing"
+import (
+    "context"
+    "errors"
+    "testing"
+)
 
 type testPair struct {
     nfilter namedFilter
@@ -36,6 +40,23 @@ var tests = []testPair{
     testPair{nSRGBEnc, XYZ{0.001, 0.5, -1}, XYZ{0.01292, 0.735356, -12.92}},
     testPair{nSRGBDec, XYZ{0.01292, 0.735356, -12.92}, XYZ{0.001, 0.5, -1}},
 
+    // PQ and HLG
+    testPair{namedFilter{PQCurve{}.GetEncoder(), "PQCurve{}.GetEncoder()"}, XYZ{0.01, 0, -1}, XYZ{0.508078, 0, 0}},
+    testPair{namedFilter{PQCurve{1000}.GetEncoder(), "PQCurve{1000}.GetEncoder()"}, XYZ{1, 0.1, 0}, XYZ{0.751827, 0.508078, 0}},
+    testPair{namedFilter{Chain(PQCurve{1000}.GetEncoder(), PQCurve{1000}.GetDecoder()), "PQRoundTrip"}, XYZ{0.2, 0.5, 0.8}, XYZ{0.2, 0.5, 0.8}},
+    testPair{namedFilter{HLGCurve{}.GetEncoder(), "HLGCurve{}.GetEncoder()"}, XYZ{1.0 / 12, 1, 0}, XYZ{0.5, 1, 0}},
+    testPair{namedFilter{Chain(HLGCurve{1.2}.GetEncoder(), HLGCurve{1.2}.GetDecoder()), "HLGRoundTrip"}, XYZ{0.01, 0.5, 0.8}, XYZ{0.01, 0.5, 0.8}},
+    testPair{namedFilter{Chain(HLGOOTF{1.2}.GetDecoder(), HLGOOTF{1.2}.GetEncoder()), "HLGOOTFRoundTrip"}, RGB{0.5, 0.2, 0.1}, RGB{0.5, 0.2, 0.1}},
+    testPair{namedFilter{HLGOOTF{}.GetDecoder(), "HLGOOTF{}.GetDecoder()"}, RGB{0.5, 0.5, 0.5}, RGB{0.435275, 0.435275, 0.435275}},
+    testPair{namedFilter{Chain(HLGOOTF{}.GetDecoder(), HLGOOTF{}.GetEncoder()), "HLGOOTFDefaultRoundTrip"}, RGB{0.5, 0.2, 0.1}, RGB{0.5, 0.2, 0.1}},
+
+    // BT.1886
+    testPair{namedFilter{BT1886Curve{100, 0.1, false}.GetDecoder(), "BT1886Curve{100, 0.1, false}.GetDecoder()"}, XYZ{0, 0.5, 1}, XYZ{0.001, 0.216049, 1}},
+    testPair{namedFilter{BT1886Curve{100, 0, false}.GetDecoder(), "BT1886Curve{100, 0, false}.GetDecoder()"}, XYZ{0, 0.5, 1}, XYZ{0, 0.189465, 1}},
+    testPair{namedFilter{BT1886Curve{100, 0.1, true}.GetDecoder(), "BT1886Curve{100, 0.1, true}.GetDecoder()"}, XYZ{0, 0.5, 1}, XYZ{0.001, 0.205735, 1}},
+    testPair{namedFilter{Chain(BT1886Curve{120, 0.05, false}.GetDecoder(), BT1886Curve{120, 0.05, false}.GetEncoder()), "BT1886RoundTrip"}, XYZ{0.01, 0.5, 0.8}, XYZ{0.01, 0.5, 0.8}},
+    testPair{namedFilter{Chain(BT1886Curve{120, 0.05, true}.GetDecoder(), BT1886Curve{120, 0.05, true}.GetEncoder()), "BT1886Annex1RoundTrip"}, XYZ{0.01, 0.35, 0.8}, XYZ{0.01, 0.35, 0.8}},
+
     // Round trip 1
     testPair{namedFilter{Chain(Identity, Invert, Invert, nLStarEnc.filter, nLStarDec.filter),"RoundTrip1"},
         XYZ{0.2, 0.5, 0.8}, XYZ{0.2, 0.5, 0.8}},
@@ -58,7 +79,27 @@ var tests = []testPair{
     testPair{namedFilter{SpacesRGB.GetDecoder(), "SpacesRGB.GetDecoder()"}, RGB{1.0, 1.0, 1.0}, PointD65},
 
     // Chromatic adaptation
-    testPair{namedFilter{ChromaticAdapter{PointD65, PointD50, Bradford}, "ChromaticAdapter{PointD65, PointD50, Bradford}"}, PointD65, PointD50},
+    testPair{namedFilter{ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}, "ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}"}, PointD65, PointD50},
+
+    // L*a*b* and L*C*h
+    testPair{namedFilter{LabSpace{PointD65, LStarIntent}.GetEncoder(), "LabSpace{PointD65, LStarIntent}.GetEncoder()"}, PointD65, Lab{1, 0, 0}},
+    testPair{namedFilter{Chain(SpacesRGB.GetDecoder(), LabSpace{PointD65, LStarIntent}.GetEncoder()), "Chain(SpacesRGB.GetDecoder(), LabSpace{PointD65, LStarIntent}.GetEncoder())"},
+        RGB{1, 0, 0}, Lab{0.532408, 0.800925, 0.672032}},
+    testPair{namedFilter{Chain(LabSpace{PointD50, LStarActual}.GetEncoder(), LabSpace{PointD50, LStarActual}.GetDecoder()), "LabRoundTrip"},
+        XYZ{0.2, 0.4, 0.8}, XYZ{0.2, 0.4, 0.8}},
+    testPair{namedFilter{LabtoLCh, "LabtoLCh"}, Lab{0.532408, 0.800925, 0.672032}, LCh{0.532408, 1.045518, 0.111108}},
+    testPair{namedFilter{Chain(LabtoLCh, LChtoLab), "Chain(LabtoLCh, LChtoLab)"}, Lab{0.5, -0.2, -0.3}, Lab{0.5, -0.2, -0.3}},
+    testPair{namedFilter{LabSpace{PointD65, LStarIntent}.GetDecoder(), "LabSpace{PointD65, LStarIntent}.GetDecoder()"}, LCh{1, 0, 0.5}, PointD65},
+
+    // u'v', L*u*v* and L*C*h(uv)
+    testPair{namedFilter{XYZtoYuv, "XYZtoYuv"}, PointD65, Yuv{1, 0.197840, 0.468336}},
+    testPair{namedFilter{Chain(XYZtoYuv, YuvtoXYZ), "Chain(XYZtoYuv, YuvtoXYZ)"}, XYZ{0.2, 0.4, 0.8}, XYZ{0.2, 0.4, 0.8}},
+    testPair{namedFilter{Chain(SpacesRGB.GetDecoder(), LuvSpace{PointD65, LStarIntent}.GetEncoder()), "Chain(SpacesRGB.GetDecoder(), LuvSpace{PointD65, LStarIntent}.GetEncoder())"},
+        RGB{1, 0, 0}, Luv{0.532408, 1.750151, 0.377564}},
+    testPair{namedFilter{Chain(LuvSpace{PointD50, LStarActual}.GetEncoder(), LuvtoLChuv, LuvSpace{PointD50, LStarActual}.GetDecoder()), "LuvRoundTrip"},
+        XYZ{0.2, 0.4, 0.8}, XYZ{0.2, 0.4, 0.8}},
+    testPair{namedFilter{LuvSpace{PointD65, LStarIntent}.GetEncoder(), "LuvSpace{PointD65, LStarIntent}.GetEncoder()"}, XYZ{0, 0, 0}, Luv{0, 0, 0}},
+    testPair{namedFilter{Chain(LuvtoLChuv, LChuvtoLuv), "Chain(LuvtoLChuv, LChuvtoLuv)"}, Luv{0.5, 0.3, -0.1}, Luv{0.5, 0.3, -0.1}},
 
     // Pullup / pulldown
     testPair{namedFilter{Pullup{8, true}, "Pullup{8, true}"}, XYZ{1, 0, 0.5}, XYZ{255, 0, 127.5}},
@@ -70,6 +111,16 @@ var tests = []testPair{
     testPair{namedFilter{Pullup{16, false}, "Pullup{16, false}"}, XYZ{1, 0, 1}, XYZ{60160, 4096, 60160}},
     testPair{namedFilter{Pullup{10, false}, "Pullup{10, false}"}, XYZ{1, 0, 1}, XYZ{940, 64, 940}},
 
+    testPair{namedFilter{ChromaPullup{8, false}, "ChromaPullup{8, false}"}, XYZ{-0.5, 0, 0.5}, XYZ{16, 128, 240}},
+    testPair{namedFilter{ChromaPulldown{10, false}, "ChromaPulldown{10, false}"}, XYZ{64, 512, 960}, XYZ{-0.5, 0, 0.5}},
+    testPair{namedFilter{ChromaPullup{8, true}, "ChromaPullup{8, true}"}, XYZ{-0.5, 0, 0.5}, XYZ{0.5, 128, 255.5}},
+
+    // Y'CbCr
+    testPair{namedFilter{YCbCrBT709.GetEncoder(), "YCbCrBT709.GetEncoder()"}, RGB{1, 0, 0}, YCbCr{0.2126, -0.114572, 0.5}},
+    testPair{namedFilter{YCbCrBT601.GetEncoder(), "YCbCrBT601.GetEncoder()"}, RGB{1, 1, 1}, YCbCr{1, 0, 0}},
+    testPair{namedFilter{Chain(YCbCrBT2020.GetEncoder(), YCbCrBT2020.GetDecoder()), "Chain(YCbCrBT2020.GetEncoder(), YCbCrBT2020.GetDecoder())"},
+        RGB{0.2, 0.5, 0.9}, RGB{0.2, 0.5, 0.9}},
+
     // Some more XYZ encoding/decoding
     testPair{namedFilter{Chain(SpacesRGB.GetDecoder(), XYZSpace(SpaceProPhotoRGB).GetEncoder(), SRGBCurve.GetEncoder()), "Chain1"}, RGB{1, 0, 0}, RGB{0.735224, 0.343268, 0.165794}},
 }
@@ -80,3 +131,238 @@ func TestFilters(t *testing.T) {
         FuzzyAssertTriple(tp.input, res, tp.output, allow, tp.nfilter.name, t)
     }
 }
+
+func TestChecked(t *testing.T) {
+    // Type mismatches are reported while building
+    bad := []interface{}{
+        Chain(SpacesRGB.Decoder(), Typed(Invert, testTriple{})),
+        Chain(Identity, Typed(Identity, testTriple{})),
+        Chain(SpacesRGB.Decoder(), Chain(Typed(Invert, testTriple{}), SpacesRGB.Decoder())),
+    }
+
+    for i, f := range bad {
+        if _, err := BuildChecked(f, RGB{}); !errors.Is(err, ErrUnsupportedType) {
+            t.Errorf("BuildChecked(bad[%d]) = %v, want %v", i, err, ErrUnsupportedType)
+        }
+    }
+
+    if _, err := BuildChecked(Swap{AB, SwapMode(7)}, XYZ{}); err == nil {
+        t.Errorf("BuildChecked(Swap{AB, 7}) succeeded")
+    }
+
+    // Declared types are checked without calling the filter
+    if _, err := BuildChecked(Typed(XYZtoYxy, XYZ{}), RGB{}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(Typed(XYZtoYxy, XYZ{}), RGB{}) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    // Plain filter functions declare nothing, their own checks report the type on the first call
+    plain, err := BuildChecked(Chain(SpacesRGB.GetDecoder(), Grayscale), RGB{})
+    if err != nil {
+        t.Fatalf("BuildChecked(Chain(SpacesRGB.GetDecoder(), Grayscale)) = %v", err)
+    }
+
+    if _, err := plain(RGB{}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(Chain(SpacesRGB.GetDecoder(), Grayscale))(RGB{}) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    calls := 0
+    counted := FilterTriple(func(in Triple) Triple {
+        calls++
+        return in.(XYZ)
+    })
+
+    g, err := BuildChecked(Chain(counted, Identity), RGB{})
+    if err != nil || calls != 0 {
+        t.Errorf("BuildChecked(counted) = %v after %d calls, want no calls", err, calls)
+    }
+
+    // A failed type assertion is a bug in the filter, not an unsupported type
+    if _, err := g(RGB{}); err == nil || errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(counted)(RGB{}) = %v, want a type assertion error", err)
+    }
+
+    if _, err := BuildChecked(42, XYZ{}); !errors.Is(err, ErrInvalidProvider) {
+        t.Errorf("BuildChecked(42) = %v, want %v", err, ErrInvalidProvider)
+    }
+
+    // Valid chains work as usual, but reject other input types
+    f, err := BuildChecked(Chain(SpacesRGB.GetDecoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}), RGB{})
+    if err != nil {
+        t.Fatalf("BuildChecked(valid) = %v", err)
+    }
+
+    if res, err := f(RGB{1, 1, 1}); err != nil || !FuzzyCompareTriple(res, PointD50, allow) {
+        t.Errorf("BuildChecked(valid)(RGB{1, 1, 1}) = %v, %v, want %v", res, err, PointD50)
+    }
+
+    if _, err := f(XYZ{1, 1, 1}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(valid)(XYZ{1, 1, 1}) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    // Other checked functions
+    if _, err := LuminanceChecked(nil); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("LuminanceChecked(nil) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    if _, err := FromTemperatureChecked(1000); !errors.Is(err, ErrOutOfRange) {
+        t.Errorf("FromTemperatureChecked(1000) = %v, want %v", err, ErrOutOfRange)
+    }
+
+    if _, err := Make3DLUTChecked([]int32{4, 4, 4}, 12, RangeFull, RangeFull, EncodingBGR, EncodingBGR, SpacesRGB, SpacesRGB); err != ErrInvalidBitDepth {
+        t.Errorf("Make3DLUTChecked(depth 12) = %v, want %v", err, ErrInvalidBitDepth)
+    }
+
+    lut := Make3DLUT([]int32{4, 4, 4}, 8, RangeFull, RangeFull, EncodingBGR, EncodingXYZ, SpacesRGB, SpacesRGB)
+    if err := lut.AssignContext(context.Background(), Invert, true, AssignOptions{}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("lut.AssignContext(RGB for XYZ output) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    panicky := FilterTriple(func(in Triple) Triple {
+        if r, _, _ := in.Get(); r > 0.5 {
+            panic("[panicky] Too bright!")
+        }
+        return SpacesRGB.GetDecoder()(in)
+    })
+
+    if err := lut.AssignContext(context.Background(), panicky, true, AssignOptions{}); err == nil || err.Error() != "[panicky] Too bright!" {
+        t.Errorf("lut.AssignContext(panicky) = %v", err)
+    }
+}
+
+// A color type unknown to the conversion graph
+type testTriple struct {
+    a, b, c float64
+}
+
+func (in testTriple) Get() (a, b, c float64) {
+    return in.a, in.b, in.c
+}
+
+func (_ testTriple) Make(a, b, c float64) Triple {
+    return testTriple{a, b, c}
+}
+
+func TestConversions(t *testing.T) {
+    red := Lab{0.532408, 0.800925, 0.672032}
+
+    FuzzyAssertTriple(RGB{1, 0, 0}, Convert(RGB{1, 0, 0}, Lab{}), red, allow, "Convert(RGB, Lab)", t)
+    FuzzyAssertTriple(red, Convert(red, RGB{}), RGB{1, 0, 0}, allow, "Convert(Lab, RGB)", t)
+    FuzzyAssertTriple(LCh{0.5, 0.2, 0.3}, Convert(Convert(LCh{0.5, 0.2, 0.3}, YCbCr{}), LCh{}), LCh{0.5, 0.2, 0.3}, allow, "Convert(Convert(LCh, YCbCr), LCh)", t)
+    FuzzyAssertTriple(PointD65, Convert(PointD65, Yuv{}), Yuv{1, 0.197840, 0.468336}, allow, "Convert(XYZ, Yuv)", t)
+
+    conv := Converter{Space: SpaceAdobeRGB98, Mode: LStarIntent, Matrix: YCbCrBT601}
+    FuzzyAssertTriple(RGB{1, 1, 1}, conv.Convert(RGB{1, 1, 1}, Lab{}), Lab{1, 0, 0}, allow, "conv.Convert(RGB, Lab)", t)
+
+    // Chains insert conversions where needed
+    in := RGB{0.2, 0.4, 0.8}
+    FuzzyAssertTriple(in, Chain(SpacesRGB.Decoder(), GrayscaleLinear).GetTriple()(in), Grayscale(in), allow, "Chain(SpacesRGB.Decoder(), GrayscaleLinear)", t)
+
+    adapted := Chain(SpacesRGB.Decoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}).GetTriple()
+    FuzzyAssertTriple(RGB{1, 1, 1}, adapted(RGB{1, 1, 1}), PointD50, allow, "Chain(SpacesRGB.Decoder(), ChromaticAdapter)", t)
+
+    // Chains are built for one input type, others need their own chain
+    fromYCbCr, err := BuildChecked(Chain(SpacesRGB.Decoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}), YCbCr{})
+    if err != nil {
+        t.Fatalf("BuildChecked(Chain(...), YCbCr{}) = %v", err)
+    }
+
+    if res, err := fromYCbCr(YCbCr{1, 0, 0}); err != nil || !FuzzyCompareTriple(res, PointD50, allow) {
+        t.Errorf("BuildChecked(Chain(...), YCbCr{})(YCbCr{1, 0, 0}) = %v, %v, want %v", res, err, PointD50)
+    }
+
+    typed, err := BuildChecked(Chain(Typed(Multiplex(Identity, Scale{0, 0.5}, Identity), LCh{})), XYZ{})
+    if err != nil {
+        t.Fatalf("BuildChecked(Chain(Typed(..., LCh{})), XYZ{}) = %v", err)
+    }
+
+    if res, err := typed(PointD65); err != nil || !FuzzyCompareTriple(res, LCh{1, 0, 0}, allow) {
+        t.Errorf("Chain(Typed(..., LCh{}))(PointD65) = %v, %v, want %v", res, err, LCh{1, 0, 0})
+    }
+
+    // Types without a conversion are reported
+    if _, err := BuildChecked(Chain(Typed(Invert, RGB{})), testTriple{}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(Chain(Typed(Invert, RGB{})), testTriple{}) = %v, want %v", err, ErrUnsupportedType)
+    }
+
+    // The converter parameters are used for the conversions
+    conv601 := Converter{Space: SpacesRGB, Mode: LStarIntent, Matrix: YCbCrBT601}
+    luma, err := BuildChecked(conv601.Chain(Typed(Identity, YCbCr{})), RGB{})
+    if err != nil {
+        t.Fatalf("BuildChecked(conv601.Chain(...)) = %v", err)
+    }
+
+    if res, err := luma(RGB{1, 0, 0}); err != nil || !FuzzyCompareTriple(res, YCbCr{0.299, -0.168736, 0.5}, allow) {
+        t.Errorf("conv601.Chain(...)(RGB{1, 0, 0}) = %v, %v, want %v", res, err, YCbCr{0.299, -0.168736, 0.5})
+    }
+}
+
+func TestLinearFusion(t *testing.T) {
+    adapter := ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}
+    swap := Swap{AB, BC}
+    scale := Scale{0.1, 0.9}
+
+    fused := Chain(SpacesRGB.Decoder(), adapter, Chain(XYZSpace(SpaceProPhotoRGB).Encoder(), swap), scale).GetTriple()
+    steps := []FilterTripleProvider{SpacesRGB.Decoder(), adapter, XYZSpace(SpaceProPhotoRGB).Encoder(), swap, Multiplex(scale, scale, scale)}
+
+    for _, in := range []RGB{{0, 0, 0}, {1, 1, 1}, {0.2, 0.4, 0.8}, {0.9, 0.1, 0.5}} {
+        var want Triple = in
+        for _, f := range steps {
+            want = f.GetTriple()(want)
+        }
+
+        FuzzyAssertTriple(in, fused(in), want, allow, "Chain(linear filters)", t)
+    }
+
+    // The plain encoders and decoders of spaces give the same results
+    plain := Chain(SpacesRGB.GetDecoder(), adapter, XYZSpace(SpaceProPhotoRGB).GetEncoder()).GetTriple()
+    linear := Chain(SpacesRGB.Decoder(), adapter, XYZSpace(SpaceProPhotoRGB).Encoder()).GetTriple()
+
+    for _, in := range []RGB{{0, 0, 0}, {1, 1, 1}, {0.2, 0.4, 0.8}} {
+        FuzzyAssertTriple(in, plain(in), linear(in), allow, "Chain(SpacesRGB.GetDecoder(), adapter, XYZSpace.GetEncoder())", t)
+    }
+
+    // Fused filters must still reject the wrong input type
+    if _, err := BuildChecked(adapter.linear().then(swap.linear()), RGB{}); !errors.Is(err, ErrUnsupportedType) {
+        t.Errorf("BuildChecked(fused adapter, RGB) = %v, want %v.", err, ErrUnsupportedType)
+    }
+
+    // Chains built for XYZ still take Yxy, like the filters on their own
+    toRGB := Chain(adapter, XYZSpace(SpaceProPhotoRGB).Encoder()).GetTriple()
+    FuzzyAssertTriple(PointD65.ToYxy(), toRGB(PointD65.ToYxy()), toRGB(PointD65), allow, "Chain(adapter, XYZSpace.Encoder())", t)
+
+    // Filters are only fused if the types match, the scaled Yxy values are converted to XYZ after scaling
+    yxy := Yxy{0.5, 0.3, 0.3}
+    FuzzyAssertTriple(yxy, Chain(scale, adapter).GetTriple()(yxy), XYZ{0.511756, 0.501990, 0.356840}, allow, "Chain(scale, adapter)", t)
+    FuzzyAssertTriple(yxy, Chain(scale, adapter).GetTriple()(yxy), adapter.GetTriple()(Multiplex(scale, scale, scale).GetTriple()(yxy)), allow, "Chain(scale, adapter) unfused", t)
+
+    // Fused filters check their input type on every call, not only the first ones in the chain
+    func() {
+        defer func() {
+            if err, _ := recover().(error); !errors.Is(err, ErrUnsupportedType) {
+                t.Errorf("Chain(Invert, adapter)(RGB) panicked with %v, want %v", err, ErrUnsupportedType)
+            }
+        }()
+        Chain(Invert, adapter).GetTriple()(RGB{0.2, 0.3, 0.4})
+    }()
+}
+
+var chainSink Triple
+
+// Linear light only, the transfer curves are not fused and would dominate
+func BenchmarkChain(b *testing.B) {
+    dec, adapter, enc := XYZSpace(SpacesRGB).GetDecoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}.GetTriple(), XYZSpace(SpaceProPhotoRGB).GetEncoder()
+    fused := Chain(XYZSpace(SpacesRGB).Decoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}, XYZSpace(SpaceProPhotoRGB).Encoder()).GetTriple()
+    in := RGB{0.2, 0.4, 0.8}
+
+    b.Run("fused", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            chainSink = fused(in)
+        }
+    })
+
+    b.Run("separate", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            chainSink = enc(adapter(dec(in)))
+        }
+    })
+}
@@ -0,0 +1,448 @@
+package colorplus
+
+import "math"
+
+// Gamut queries. Membership is tested against the full gamut volume (linear RGB in the range 0-1), so colors brighter
+// than the white point of the space are out of gamut even if their chromaticity is inside
+
+// Chromaticity diagrams for comparing gamut areas
+type Diagram byte
+
+const (
+    DiagramXY Diagram = iota // CIE 1931 xy
+    DiagramUV                // CIE 1976 u'v', perceptually more uniform
+)
+
+const gamutEpsilon = 1e-9 // tolerance for rounding errors in membership tests
+
+// Whether a color is inside the gamut of the space, RGB values are assumed to be linear and in this space already
+func (s Space) Contains(in Triple) bool {
+    return s.GamutDistance(in) <= gamutEpsilon
+}
+
+// How far a color lies outside the gamut, as the largest excess of a linear RGB channel beyond 0-1 (0 if inside)
+func (s Space) GamutDistance(in Triple) float64 {
+    return gamutDistance(s.linearRGB(in))
+}
+
+// Area of the gamut triangle in the given diagram
+func (s Space) AreaIn(d Diagram) float64 {
+    return polygonArea(s.triangle(d))
+}
+
+// Area of the overlap between the gamut triangles of both spaces
+func (s Space) Intersection(o Space, d Diagram) float64 {
+    return polygonArea(clipPolygon(o.triangle(d), s.triangle(d)))
+}
+
+// Fraction of the gamut of o that is covered by s, e.g. a display covering 95% of SpaceDCI_P3
+func (s Space) Coverage(o Space, d Diagram) float64 {
+    return s.Intersection(o, d) / o.AreaIn(d)
+}
+
+// Helpers
+func (s Space) linearRGB(in Triple) Matrix1x3 {
+    var x XYZ
+
+    switch v := in.(type) {
+        case RGB: return Matrix1x3{v.R, v.G, v.B}
+        case XYZ: x = v
+        case Yxy: x = v.ToXYZ()
+        default: panic(unsupported("Space", in))
+    }
+
+    return s.XYZToRGB().Mul1x3(Matrix1x3{x.X, x.Y, x.Z})
+}
+
+// The largest excess of a linear RGB channel beyond 0-1
+func gamutDistance(c Matrix1x3) float64 {
+    return math.Max(0, math.Max(-math.Min(c.M1, math.Min(c.M2, c.M3)), math.Max(c.M1, math.Max(c.M2, c.M3)) - 1))
+}
+
+type point2D struct {
+    x, y float64
+}
+
+// Primaries in counter-clockwise order
+func (s Space) triangle(d Diagram) []point2D {
+    var res []point2D
+
+    for _, p := range []XYZ{s.Red, s.Green, s.Blue} {
+        if (d == DiagramUV) {
+            uv := p.ToYuv()
+            res = append(res, point2D{uv.U, uv.V})
+        } else {
+            xy := p.ToYxy()
+            res = append(res, point2D{xy.x, xy.y})
+        }
+    }
+
+    if (signedArea(res) < 0) {
+        res[1], res[2] = res[2], res[1]
+    }
+    return res
+}
+
+// Shoelace formula, positive for counter-clockwise polygons
+func signedArea(poly []point2D) float64 {
+    var a float64
+
+    for i, p := range poly {
+        q := poly[(i + 1) % len(poly)]
+        a += p.x * q.y - q.x * p.y
+    }
+
+    return a / 2
+}
+
+func polygonArea(poly []point2D) float64 {
+    return math.Abs(signedArea(poly))
+}
+
+// Sutherland-Hodgman clipping of poly against the convex, counter-clockwise polygon clip
+func clipPolygon(poly, clip []point2D) []point2D {
+    for i, a := range clip {
+        b := clip[(i + 1) % len(clip)]
+        side := func(p point2D) float64 {
+            return (b.x - a.x) * (p.y - a.y) - (b.y - a.y) * (p.x - a.x)
+        }
+
+        var res []point2D
+        for j, p := range poly {
+            q := poly[(j + 1) % len(poly)]
+            sp, sq := side(p), side(q)
+
+            if (sp >= 0) {
+                res = append(res, p)
+            }
+            if ((sp >= 0) != (sq >= 0)) {
+                t := sp / (sp - sq)
+                res = append(res, point2D{p.x + (q.x - p.x) * t, p.y + (q.y - p.y) * t})
+            }
+        }
+
+        if (len(res) == 0) {
+            return nil
+        }
+        poly = res
+    }
+
+    return poly
+}
+
+// Gamut mapping filters, mapping XYZ colors into the gamut of the destination space (the output is XYZ as well, so
+// follow with the encoder of the space)
+
+// Per-channel clipping of linear RGB, simple but shifts hue and luminance
+type GamutClip struct {
+    Space Space
+}
+
+// Desaturation toward the gray of the same luminance, colors brighter than white or darker than black are clipped
+type GamutLuminanceClip struct {
+    Space Space
+}
+
+// Chroma reduction at constant lightness and hue in CIE LCh, relative to the white of the space
+type GamutChromaReduction struct {
+    Space Space
+    Mode LStarCurve
+}
+
+// Soft-knee compression of the distance from the achromatic axis, like the ACES reference gamut compression. Distances
+// below Threshold are unchanged, and distances up to Limit (per channel, for cyan, magenta and yellow) are compressed
+// into the gamut, with Power controlling the shape of the knee. Zero fields use the ACES reference parameters. Only
+// negative components are compressed, values above 1 are left for tone mapping
+type GamutCompression struct {
+    Space Space
+    Threshold, Limit RGB
+    Power float64
+}
+
+var (
+    acesThreshold = RGB{0.815, 0.803, 0.880}
+    acesLimit = RGB{1.147, 1.264, 1.312}
+)
+
+const acesPower = 1.2
+
+func (gc GamutClip) InputType() Triple {
+    return XYZ{}
+}
+
+func (gc GamutClip) GetTriple() FilterTriple {
+    clamp := Clamp{0, 1}.GetSingle()
+
+    return gc.Space.gamutFilter("GamutClip", func(c Matrix1x3) Matrix1x3 {
+        return Matrix1x3{clamp(c.M1), clamp(c.M2), clamp(c.M3)}
+    })
+}
+
+func (gl GamutLuminanceClip) InputType() Triple {
+    return XYZ{}
+}
+
+func (gl GamutLuminanceClip) GetTriple() FilterTriple {
+    m := gl.Space.RGBToXYZ()
+
+    return gl.Space.gamutFilter("GamutLuminanceClip", func(c Matrix1x3) Matrix1x3 {
+        Y := m.M21 * c.M1 + m.M22 * c.M2 + m.M23 * c.M3
+        if (Y <= 0) {
+            return Matrix1x3{}
+        }
+        if (Y >= 1) {
+            return Matrix1x3{1, 1, 1}
+        }
+
+        // Largest fraction of the distance from gray that stays within 0-1 for every channel
+        t := 1.0
+        for _, v := range []float64{c.M1, c.M2, c.M3} {
+            if (v > 1) {
+                t = math.Min(t, (1 - Y) / (v - Y))
+            } else if (v < 0) {
+                t = math.Min(t, Y / (Y - v))
+            }
+        }
+
+        return Matrix1x3{Y + (c.M1 - Y) * t, Y + (c.M2 - Y) * t, Y + (c.M3 - Y) * t}
+    })
+}
+
+func (gr GamutChromaReduction) InputType() Triple {
+    return XYZ{}
+}
+
+func (gr GamutChromaReduction) GetTriple() FilterTriple {
+    white, forw := gr.Space.White, gr.Space.XYZToRGB()
+
+    contains := func(x XYZ) bool {
+        return gamutDistance(forw.Mul1x3(Matrix1x3{x.X, x.Y, x.Z})) <= gamutEpsilon
+    }
+
+    return func(in Triple) Triple {
+        var x XYZ
+        switch v := in.(type) {
+            case XYZ: x = v
+            case Yxy: x = v.ToXYZ()
+            default: panic(unsupported("GamutChromaReduction", in))
+        }
+
+        if (contains(x)) {
+            return x
+        }
+
+        lch := x.ToLab(white, gr.Mode).ToLCh()
+        if (lch.L >= 1) {
+            return white
+        }
+        if (lch.L <= 0) {
+            return XYZ{}
+        }
+
+        // Bisect for the largest chroma still inside the gamut
+        lo, hi := 0.0, lch.C
+        for i := 0; i < 32; i++ {
+            mid := (lo + hi) / 2
+            if (contains(LCh{lch.L, mid, lch.H}.ToLab().ToXYZ(white, gr.Mode))) {
+                lo = mid
+            } else {
+                hi = mid
+            }
+        }
+
+        return LCh{lch.L, lo, lch.H}.ToLab().ToXYZ(white, gr.Mode)
+    }
+}
+
+func (gc GamutCompression) InputType() Triple {
+    return XYZ{}
+}
+
+func (gc GamutCompression) GetTriple() FilterTriple {
+    thr, lim, p := gc.Threshold, gc.Limit, gc.Power
+    if (thr == RGB{}) {
+        thr = acesThreshold
+    }
+    if (lim == RGB{}) {
+        lim = acesLimit
+    }
+    if (p == 0) {
+        p = acesPower
+    }
+
+    cr, cg, cb := compressionCurve(thr.R, lim.R, p), compressionCurve(thr.G, lim.G, p), compressionCurve(thr.B, lim.B, p)
+
+    return gc.Space.gamutFilter("GamutCompression", func(c Matrix1x3) Matrix1x3 {
+        ach := math.Max(c.M1, math.Max(c.M2, c.M3))
+        if (ach == 0) {
+            return c
+        }
+
+        // Distances from the achromatic axis, 1 is on the gamut boundary
+        a := math.Abs(ach)
+        return Matrix1x3{ach - cr((ach - c.M1) / a) * a, ach - cg((ach - c.M2) / a) * a, ach - cb((ach - c.M3) / a) * a}
+    })
+}
+
+// Compression function mapping the distance lim onto 1, leaving distances below thr unchanged
+func compressionCurve(thr, lim, p float64) FilterSingle {
+    scale := (lim - thr) / math.Pow(math.Pow((1 - thr) / (lim - thr), -p) - 1, 1 / p)
+
+    return func(d float64) float64 {
+        if (d < thr) {
+            return d
+        }
+
+        n := (d - thr) / scale
+        return thr + scale * n / math.Pow(1 + math.Pow(n, p), 1 / p)
+    }
+}
+
+// Wraps a function on linear RGB in the space as a filter on XYZ
+func (s Space) gamutFilter(name string, f func(Matrix1x3) Matrix1x3) FilterTriple {
+    forw, back := s.XYZToRGB(), s.RGBToXYZ()
+
+    return func(in Triple) Triple {
+        var x XYZ
+        switch v := in.(type) {
+            case XYZ: x = v
+            case Yxy: x = v.ToXYZ()
+            default: panic(unsupported(name, in))
+        }
+
+        res := back.Mul1x3(f(forw.Mul1x3(Matrix1x3{x.X, x.Y, x.Z})))
+        return XYZ{res.M1, res.M2, res.M3}
+    }
+}
+
+// Gamut volumes in a perceptual space, which must be Cartesian (such as Lab or Luv). All spaces are converted relative
+// to the same White so that their volumes are comparable. Volumes are in the normalized units of the target, so a Lab
+// volume of 1 corresponds to 10⁶ in the usual units. Steps is the number of samples along each axis (default 32)
+type VolumeSpace struct {
+    Target Triple
+    White XYZ
+    Mode LStarCurve
+    Steps int
+}
+
+var (
+    VolumeCIELab = VolumeSpace{Lab{}, PointD65, LStarIntent, 32}
+    VolumeCIELuv = VolumeSpace{Luv{}, PointD65, LStarIntent, 32}
+)
+
+// Volume enclosed by the surface of the RGB cube, mapped into the perceptual space
+func (s Space) Volume(vs VolumeSpace) float64 {
+    var vol float64
+
+    for _, t := range vs.surface(s) {
+        a, b, c := t[0], t[1], t[2]
+        vol += a.M1 * (b.M2 * c.M3 - b.M3 * c.M2) - a.M2 * (b.M1 * c.M3 - b.M3 * c.M1) + a.M3 * (b.M1 * c.M2 - b.M2 * c.M1)
+    }
+
+    return math.Abs(vol) / 6
+}
+
+// Volume of the overlap of both gamuts
+func (s Space) IntersectionVolume(o Space, vs VolumeSpace) float64 {
+    _, both := vs.sampleGrid(o, s)
+    return both
+}
+
+// Fraction of the gamut volume of o that is covered by s, e.g. a display covering 95% of the SpaceDCI_P3 volume
+func (s Space) VolumeCoverage(o Space, vs VolumeSpace) float64 {
+    inside, both := vs.sampleGrid(o, s)
+    return both / inside
+}
+
+// Triangulated surface of the RGB cube in the perceptual space, consistently oriented
+func (vs VolumeSpace) surface(s Space) [][3]Matrix1x3 {
+    conv, err := Converter{Space: s, White: vs.White, Mode: vs.Mode}.GetConversion(RGB{}, vs.Target)
+    if err != nil {
+        panic(err)
+    }
+
+    n := vs.steps()
+    var res [][3]Matrix1x3
+
+    for axis := 0; axis < 3; axis++ {
+        for side := 0; side < 2; side++ {
+            // Points on the face, with the other two axes following in cyclic order
+            point := func(i, j int) Matrix1x3 {
+                var c [3]float64
+                c[axis], c[(axis + 1) % 3], c[(axis + 2) % 3] = float64(side), float64(i) / float64(n), float64(j) / float64(n)
+
+                a, b, d := conv(RGB{c[0], c[1], c[2]}).Get()
+                return Matrix1x3{a, b, d}
+            }
+
+            for i := 0; i < n; i++ {
+                for j := 0; j < n; j++ {
+                    p00, p10, p11, p01 := point(i, j), point(i + 1, j), point(i + 1, j + 1), point(i, j + 1)
+
+                    // The cyclic axis order makes (i, j) right-handed, so the face at 0 is flipped to point outward
+                    if (side == 0) {
+                        res = append(res, [3]Matrix1x3{p00, p11, p10}, [3]Matrix1x3{p00, p01, p11})
+                    } else {
+                        res = append(res, [3]Matrix1x3{p00, p10, p11}, [3]Matrix1x3{p00, p11, p01})
+                    }
+                }
+            }
+        }
+    }
+
+    return res
+}
+
+// Sample a regular grid over the bounding box of the gamut of box, returning the volume inside box and the volume
+// inside both box and other
+func (vs VolumeSpace) sampleGrid(box, other Space) (inside, both float64) {
+    conv, err := Converter{White: vs.White, Mode: vs.Mode}.GetConversion(vs.Target, XYZ{})
+    if err != nil {
+        panic(err)
+    }
+
+    lo := Matrix1x3{math.Inf(1), math.Inf(1), math.Inf(1)}
+    hi := Matrix1x3{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+    for _, t := range vs.surface(box) {
+        for _, p := range t {
+            lo = Matrix1x3{math.Min(lo.M1, p.M1), math.Min(lo.M2, p.M2), math.Min(lo.M3, p.M3)}
+            hi = Matrix1x3{math.Max(hi.M1, p.M1), math.Max(hi.M2, p.M2), math.Max(hi.M3, p.M3)}
+        }
+    }
+
+    n := 2 * vs.steps()
+    step := Matrix1x3{(hi.M1 - lo.M1) / float64(n), (hi.M2 - lo.M2) / float64(n), (hi.M3 - lo.M3) / float64(n)}
+    mb, mo := box.XYZToRGB(), other.XYZToRGB()
+
+    contains := func(m Matrix3x3, x XYZ) bool {
+        c := m.Mul1x3(Matrix1x3{x.X, x.Y, x.Z})
+        return math.Min(c.M1, math.Min(c.M2, c.M3)) >= -gamutEpsilon && math.Max(c.M1, math.Max(c.M2, c.M3)) <= 1 + gamutEpsilon
+    }
+
+    var ni, nb int
+    for i := 0; i < n; i++ {
+        for j := 0; j < n; j++ {
+            for k := 0; k < n; k++ {
+                p := vs.Target.Make(lo.M1 + (float64(i) + 0.5) * step.M1, lo.M2 + (float64(j) + 0.5) * step.M2, lo.M3 + (float64(k) + 0.5) * step.M3)
+                x := conv(p).(XYZ)
+
+                if (contains(mb, x)) {
+                    ni++
+                    if (contains(mo, x)) {
+                        nb++
+                    }
+                }
+            }
+        }
+    }
+
+    cell := step.M1 * step.M2 * step.M3
+    return float64(ni) * cell, float64(nb) * cell
+}
+
+func (vs VolumeSpace) steps() int {
+    if (vs.Steps == 0) {
+        return 32
+    }
+    return vs.Steps
+}
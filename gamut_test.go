@@ -0,0 +1,87 @@
+package colorplus
+
+import "testing"
+
+func TestGamut(t *testing.T) {
+    contains := []struct {
+        space Space
+        in Triple
+        want bool
+    }{
+        {SpacesRGB, PointD65, true},
+        {SpacesRGB, Yxy{0.5, 0.3127, 0.3290}, true},
+        {SpacesRGB, Yxy{0.1, 0.17, 0.70}, false},
+        {SpaceBT2020, Yxy{0.1, 0.17, 0.70}, true},
+        {SpacesRGB, XYZ{2, 2, 2}, false},
+        {SpacesRGB, RGB{0.5, 1, 0}, true},
+    }
+
+    for _, v := range contains {
+        if res := v.space.Contains(v.in); res != v.want {
+            t.Errorf("Contains(%v) = %v, want %v.", v.in, res, v.want)
+        }
+    }
+
+    FuzzyAssertSingle(RGB{1.2, 0.5, -0.1}, SpacesRGB.GamutDistance(RGB{1.2, 0.5, -0.1}), 0.2, allow, "GamutDistance", t)
+    FuzzyAssertSingle(PointD65, SpacesRGB.GamutDistance(PointD65), 0, allow, "GamutDistance", t)
+
+    // Nested gamuts
+    FuzzyAssertSingle("", SpaceBT2020.Coverage(SpacesRGB, DiagramXY), 1, allow, "SpaceBT2020.Coverage(SpacesRGB)", t)
+    FuzzyAssertSingle("", SpacesRGB.Coverage(SpaceBT2020, DiagramUV), SpacesRGB.AreaIn(DiagramUV) / SpaceBT2020.AreaIn(DiagramUV), allow, "SpacesRGB.Coverage(SpaceBT2020)", t)
+    FuzzyAssertSingle("", SpacesRGB.AreaIn(DiagramXY), SpacesRGB.Area(), allow, "SpacesRGB.AreaIn", t)
+
+    // Partially overlapping gamuts
+    FuzzyAssertSingle("", SpacesRGB.Intersection(SpaceNTSC_53, DiagramXY), SpaceNTSC_53.Intersection(SpacesRGB, DiagramXY), allow, "SpacesRGB.Intersection(SpaceNTSC_53)", t)
+    FuzzyAssertSingle("", SpacesRGB.Coverage(SpaceNTSC_53, DiagramXY), 0.684556, allow, "SpacesRGB.Coverage(SpaceNTSC_53)", t)
+}
+
+func TestGamutMapping(t *testing.T) {
+    green := Yxy{0.4, 0.2, 0.65}.ToXYZ() // inside BT.2020, outside sRGB
+    mild := Yxy{0.3, 0.25, 0.5}.ToXYZ()
+
+    for _, f := range []namedFilter{
+        {GamutClip{SpacesRGB}, "GamutClip"},
+        {GamutLuminanceClip{SpacesRGB}, "GamutLuminanceClip"},
+        {GamutChromaReduction{SpacesRGB, LStarIntent}, "GamutChromaReduction"},
+        {GamutCompression{Space: SpacesRGB}, "GamutCompression"},
+    } {
+        mapped := f.filter.(FilterTripleProvider).GetTriple()
+        FuzzyAssertTriple(PointD65, mapped(PointD65), PointD65, allow, f.name, t)
+
+        if res := mapped(mild); SpacesRGB.GamutDistance(res) > allow {
+            t.Errorf("%s(%v) = %v, out of gamut by %v.", f.name, mild, res, SpacesRGB.GamutDistance(res))
+        }
+    }
+
+    for _, f := range []namedFilter{
+        {GamutClip{SpacesRGB}, "GamutClip"},
+        {GamutLuminanceClip{SpacesRGB}, "GamutLuminanceClip"},
+        {GamutChromaReduction{SpacesRGB, LStarIntent}, "GamutChromaReduction"},
+    } {
+        if res := f.filter.(FilterTripleProvider).GetTriple()(green); SpacesRGB.GamutDistance(res) > allow {
+            t.Errorf("%s(%v) = %v, out of gamut by %v.", f.name, green, res, SpacesRGB.GamutDistance(res))
+        }
+    }
+
+    // Luminance, lightness and hue preservation
+    FuzzyAssertSingle(green, GamutLuminanceClip{SpacesRGB}.GetTriple()(green).(XYZ).Y, green.Y, allow, "GamutLuminanceClip", t)
+
+    want := green.ToLab(SpacesRGB.White, LStarIntent).ToLCh()
+    res := GamutChromaReduction{SpacesRGB, LStarIntent}.GetTriple()(green).(XYZ).ToLab(SpacesRGB.White, LStarIntent).ToLCh()
+    FuzzyAssertSingle(green, res.L, want.L, allow, "GamutChromaReduction", t)
+    FuzzyAssertSingle(green, res.H, want.H, allow, "GamutChromaReduction", t)
+
+    // Colors well inside the gamut are not compressed
+    inside := Yxy{0.3, 0.3, 0.33}.ToXYZ()
+    FuzzyAssertTriple(inside, GamutCompression{Space: SpacesRGB}.GetTriple()(inside), inside, allow, "GamutCompression", t)
+}
+
+func TestGamutVolume(t *testing.T) {
+    vol := SpacesRGB.Volume(VolumeCIELab)
+    FuzzyAssertSingle("", vol, 0.82, 0.01, "SpacesRGB.Volume", t) // about 820000 in the usual units
+    FuzzyAssertSingle("", SpacesRGB.IntersectionVolume(SpacesRGB, VolumeCIELab), vol, 0.01, "SpacesRGB.IntersectionVolume(SpacesRGB)", t)
+
+    // sRGB is entirely inside Display P3
+    FuzzyAssertSingle("", SpaceDisplayP3.VolumeCoverage(SpacesRGB, VolumeCIELab), 1, allow, "SpaceDisplayP3.VolumeCoverage(SpacesRGB)", t)
+    FuzzyAssertSingle("", SpacesRGB.VolumeCoverage(SpaceDisplayP3, VolumeCIELab), vol / SpaceDisplayP3.Volume(VolumeCIELab), 0.01, "SpacesRGB.VolumeCoverage(SpaceDisplayP3)", t)
+}
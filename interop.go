@@ -1,27 +1,244 @@
 package colorplus
 
 import (
+    "context"
+    "fmt"
     "image"
+    "image/color"
+    "image/draw"
     "math"
 )
 
 // Our RGB type can implement image.Color - note that colors must already be pulled up as appropriate
 func (in RGB) RGBA() (r, g, b, a uint32) {
-    return uint32(math.Floor(in.R)), uint32(math.Floor(in.G)), uint32(math.Floor(in.B)), 0xFFFF
+    return uint32(snorm(in.R / 0xFFFF, 0xFFFF)), uint32(snorm(in.G / 0xFFFF, 0xFFFF)), uint32(snorm(in.B / 0xFFFF, 0xFFFF)), 0xFFFF
 }
 
+// Apply a filter to an image in place, see ApplyImage
 func ApplyToImage(i *image.RGBA, f FilterTripleProvider) {
-    w, h := i.Rect.Max.X, i.Rect.Max.Y
-    filter := Pipeline(16, true, Chain(f, Clamp{0, 1})).GetTriple()
+    if err := ApplyImage(i, i, f); err != nil {
+        panic(err)
+    }
+}
+
+// Options for image processing. Rows are processed in bands of BandHeight rows (default 16) on Workers goroutines
+// (default GOMAXPROCS), and Progress is called with the number of rows done after every band. Dither applies when
+// the filtered colors are quantized to the depth of the destination
+type ApplyOptions struct {
+    Workers int
+    BandHeight int
+    Progress func(done, total int)
+    Dither Dither
+}
+
+// Apply a filter to every pixel of src inside the bounds of dst, writing to the same coordinates in dst (which may be
+// src itself). The filter sees non-premultiplied RGB normalized to 0-1, alpha is passed through unchanged. The common
+// image types from the image package are accessed directly, others go through At and Set
+func ApplyImage(dst draw.Image, src image.Image, f FilterTripleProvider) error {
+    return ApplyImageContext(context.Background(), dst, src, f, ApplyOptions{})
+}
+
+// Parallel version of ApplyImage, stops early and returns ctx.Err() if ctx is cancelled. The filter is built once and
+// shared between all workers. Destinations of types not in the image package are written by a single worker, since
+// their Set method need not be safe for concurrent use, and so is anything using DitherFloydSteinberg
+func ApplyImageContext(ctx context.Context, dst draw.Image, src image.Image, f FilterTripleProvider, opts ApplyOptions) error {
+    filter, out, err := getTripleChecked(Chain(f, Clamp{0, 1}), RGB{})
+    if err != nil {
+        return err
+    }
 
-    for x := 0; x < w; x++ {
-        for y := 0; y < h; y++ {
-            r, g, b, _ := i.At(x, y).RGBA()
-            i.Set(x, y, filter(RGB{float64(r), float64(g), float64(b)}).(RGB))
+    if _, ok := out.(RGB); !ok && out != nil {
+        return fmt.Errorf("[ApplyImage] %w: filter returns %T", ErrUnsupportedType, out)
+    }
+
+    r := dst.Bounds().Intersect(src.Bounds())
+    read := pixelReader(src)
+    write, native := pixelWriter(dst, makeQuantizer(opts.Dither, r))
+
+    workers, band := opts.Workers, opts.BandHeight
+    if (!native || opts.Dither == DitherFloydSteinberg) {
+        workers = 1
+    }
+    if (band <= 0) {
+        band = 16
+    }
+
+    rows := func(i int) error {
+        for y := r.Min.Y + i * band; y < r.Max.Y && y < r.Min.Y + (i + 1) * band; y++ {
+            for x := r.Min.X; x < r.Max.X; x++ {
+                c, a := read(x, y)
+                write(x, y, filter(c).(RGB), a)
+            }
         }
+        return nil
     }
+
+    total, done := r.Dy(), 0
+    progress := func(i int) {
+        if (opts.Progress != nil) {
+            done += int(math.Min(float64(band), float64(total - i * band)))
+            opts.Progress(done, total)
+        }
+    }
+
+    return parallel(ctx, workers, (total + band - 1) / band, rows, progress)
+}
+
+// Apply a filter to a copy of src, which has the same bounds and, for the common image types, the same type. Other
+// types result in an *image.NRGBA64
+func FilterImage(src image.Image, f FilterTripleProvider) (draw.Image, error) {
+    var dst draw.Image
+
+    switch i := src.(type) {
+        case *image.RGBA: dst = image.NewRGBA(i.Rect)
+        case *image.NRGBA: dst = image.NewNRGBA(i.Rect)
+        case *image.RGBA64: dst = image.NewRGBA64(i.Rect)
+        default: dst = image.NewNRGBA64(src.Bounds())
+    }
+
+    if err := ApplyImage(dst, src, f); err != nil {
+        return nil, err
+    }
+
+    return dst, nil
 }
 
 func Pipeline(depth uint, full bool, filter FilterTripleProvider) FilterTripleProvider {
     return Chain(Pulldown{depth, full}, filter, Pullup{depth, full})
 }
+
+// Pixel access, colors are non-premultiplied and normalized to 0-1
+type pixelReadFunc func(x, y int) (RGB, float64)
+type pixelWriteFunc func(x, y int, c RGB, a float64)
+
+func pixelReader(src image.Image) pixelReadFunc {
+    switch i := src.(type) {
+        case *image.NRGBA:
+            return func(x, y int) (RGB, float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                return RGB{unorm8(p[0]), unorm8(p[1]), unorm8(p[2])}, unorm8(p[3])
+            }
+
+        case *image.RGBA:
+            return func(x, y int) (RGB, float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                return unpremultiply(unorm8(p[0]), unorm8(p[1]), unorm8(p[2]), unorm8(p[3]))
+            }
+
+        case *image.NRGBA64:
+            return func(x, y int) (RGB, float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                return RGB{unorm16(p[0:]), unorm16(p[2:]), unorm16(p[4:])}, unorm16(p[6:])
+            }
+
+        case *image.RGBA64:
+            return func(x, y int) (RGB, float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                return unpremultiply(unorm16(p[0:]), unorm16(p[2:]), unorm16(p[4:]), unorm16(p[6:]))
+            }
+
+        case *image.Gray16:
+            return func(x, y int) (RGB, float64) {
+                v := unorm16(i.Pix[i.PixOffset(x, y):])
+                return RGB{v, v, v}, 1
+            }
+
+        case *image.Gray:
+            return func(x, y int) (RGB, float64) {
+                v := unorm8(i.Pix[i.PixOffset(x, y)])
+                return RGB{v, v, v}, 1
+            }
+    }
+
+    return func(x, y int) (RGB, float64) {
+        r, g, b, a := src.At(x, y).RGBA()
+        return unpremultiply(float64(r) / 0xFFFF, float64(g) / 0xFFFF, float64(b) / 0xFFFF, float64(a) / 0xFFFF)
+    }
+}
+
+// The second result reports whether dst is accessed directly. Color channels are quantized by q, alpha is rounded
+func pixelWriter(dst draw.Image, q quantizer) (pixelWriteFunc, bool) {
+    switch i := dst.(type) {
+        case *image.NRGBA:
+            return func(x, y int, c RGB, a float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                p[0], p[1], p[2], p[3] = uint8(q(c.R, 0xFF, x, y, 0)), uint8(q(c.G, 0xFF, x, y, 1)), uint8(q(c.B, 0xFF, x, y, 2)), snorm8(a)
+            }, true
+
+        case *image.RGBA:
+            return func(x, y int, c RGB, a float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                p[0], p[1], p[2], p[3] = uint8(q(c.R * a, 0xFF, x, y, 0)), uint8(q(c.G * a, 0xFF, x, y, 1)), uint8(q(c.B * a, 0xFF, x, y, 2)), snorm8(a)
+            }, true
+
+        case *image.NRGBA64:
+            return func(x, y int, c RGB, a float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                put16(p[0:], q(c.R, 0xFFFF, x, y, 0))
+                put16(p[2:], q(c.G, 0xFFFF, x, y, 1))
+                put16(p[4:], q(c.B, 0xFFFF, x, y, 2))
+                snorm16(p[6:], a)
+            }, true
+
+        case *image.RGBA64:
+            return func(x, y int, c RGB, a float64) {
+                p := i.Pix[i.PixOffset(x, y):]
+                put16(p[0:], q(c.R * a, 0xFFFF, x, y, 0))
+                put16(p[2:], q(c.G * a, 0xFFFF, x, y, 1))
+                put16(p[4:], q(c.B * a, 0xFFFF, x, y, 2))
+                snorm16(p[6:], a)
+            }, true
+
+        case *image.Gray16:
+            return func(x, y int, c RGB, a float64) {
+                put16(i.Pix[i.PixOffset(x, y):], q(grayLevel(c), 0xFFFF, x, y, 0))
+            }, true
+
+        case *image.Gray:
+            return func(x, y int, c RGB, a float64) {
+                i.Pix[i.PixOffset(x, y)] = uint8(q(grayLevel(c), 0xFF, x, y, 0))
+            }, true
+    }
+
+    return func(x, y int, c RGB, a float64) {
+        dst.Set(x, y, color.NRGBA64{uint16(q(c.R, 0xFFFF, x, y, 0)), uint16(q(c.G, 0xFFFF, x, y, 1)), uint16(q(c.B, 0xFFFF, x, y, 2)), uint16(snorm(a, 0xFFFF))})
+    }, false
+}
+
+// Helpers for sample conversion
+func unpremultiply(r, g, b, a float64) (RGB, float64) {
+    if (a == 0) {
+        return RGB{}, 0
+    }
+    return RGB{r / a, g / a, b / a}, a
+}
+
+// Same weights as color.GrayModel, so that gray images behave like the image package
+func grayLevel(c RGB) float64 {
+    return (19595 * c.R + 38470 * c.G + 7471 * c.B) / 65536
+}
+
+func unorm8(v uint8) float64 {
+    return float64(v) / 0xFF
+}
+
+func unorm16(p []uint8) float64 {
+    return float64(uint16(p[0]) << 8 | uint16(p[1])) / 0xFFFF
+}
+
+func snorm(v, max float64) float64 {
+    return math.Floor(math.Max(0, math.Min(1, v)) * max + 0.5)
+}
+
+func snorm8(v float64) uint8 {
+    return uint8(snorm(v, 0xFF))
+}
+
+func snorm16(p []uint8, v float64) {
+    put16(p, snorm(v, 0xFFFF))
+}
+
+func put16(p []uint8, code float64) {
+    s := uint16(code)
+    p[0], p[1] = uint8(s >> 8), uint8(s)
+}
@@ -0,0 +1,145 @@
+package colorplus
+
+import (
+    "context"
+    "image"
+    "image/color"
+    "reflect"
+    "testing"
+)
+
+func TestImage(t *testing.T) {
+    // Premultiplied alpha and non-zero bounds
+    rgba := image.NewRGBA(image.Rect(10, 20, 14, 23))
+    rgba.SetRGBA(11, 21, color.RGBA{100, 50, 20, 128})
+    rgba.SetRGBA(13, 22, color.RGBA{0, 0, 0, 0})
+    ApplyToImage(rgba, Chain(Invert))
+
+    if c := rgba.RGBAAt(11, 21); c != (color.RGBA{28, 78, 108, 128}) {
+        t.Errorf("ApplyToImage(RGBA) = %v, want %v.", c, color.RGBA{28, 78, 108, 128})
+    }
+    if c := rgba.RGBAAt(13, 22); c != (color.RGBA{}) {
+        t.Errorf("ApplyToImage(RGBA) = %v, want %v.", c, color.RGBA{})
+    }
+
+    // Only the intersection of the bounds is processed
+    nrgba := image.NewNRGBA(image.Rect(-5, -5, 5, 5))
+    sub := nrgba.SubImage(image.Rect(0, 0, 2, 2)).(*image.NRGBA)
+    if err := ApplyImage(sub, nrgba, Chain(Invert)); err != nil {
+        t.Errorf("ApplyImage(NRGBA) = %v.", err)
+    }
+    if c := nrgba.NRGBAAt(1, 1); c != (color.NRGBA{255, 255, 255, 0}) {
+        t.Errorf("ApplyImage(NRGBA) = %v, want %v.", c, color.NRGBA{255, 255, 255, 0})
+    }
+    if c := nrgba.NRGBAAt(2, 2); c != (color.NRGBA{}) {
+        t.Errorf("ApplyImage(NRGBA) = %v, want %v.", c, color.NRGBA{})
+    }
+
+    // 16-bit values survive unchanged
+    rgba64 := image.NewRGBA64(image.Rect(0, 0, 1, 1))
+    rgba64.SetRGBA64(0, 0, color.RGBA64{0x1234, 0x5678, 0x2345, 0x8000})
+    out, err := FilterImage(rgba64, Chain(Identity))
+    if err != nil {
+        t.Errorf("FilterImage(RGBA64) = %v.", err)
+    } else if c := out.(*image.RGBA64).RGBA64At(0, 0); c != (color.RGBA64{0x1234, 0x5678, 0x2345, 0x8000}) {
+        t.Errorf("FilterImage(RGBA64) = %v, want %v.", c, color.RGBA64{0x1234, 0x5678, 0x2345, 0x8000})
+    }
+
+    // Gray images are widened
+    gray := image.NewGray16(image.Rect(0, 0, 1, 1))
+    gray.SetGray16(0, 0, color.Gray16{0x4000})
+    out, err = FilterImage(gray, Multiplex(Identity, Invert, Identity))
+    if err != nil {
+        t.Errorf("FilterImage(Gray16) = %v.", err)
+    } else if c := out.(*image.NRGBA64).NRGBA64At(0, 0); c != (color.NRGBA64{0x4000, 0xBFFF, 0x4000, 0xFFFF}) {
+        t.Errorf("FilterImage(Gray16) = %v, want %v.", c, color.NRGBA64{0x4000, 0xBFFF, 0x4000, 0xFFFF})
+    }
+
+    // Filters that cannot work on any color type are rejected
+    never := FilterTriple(func(in Triple) Triple { panic(unsupported("never", in)) })
+    if _, err := FilterImage(gray, never); err == nil {
+        t.Errorf("FilterImage(Gray16, never) succeeded, want error.")
+    }
+}
+
+func TestImageParallel(t *testing.T) {
+    chain := Chain(SpacesRGB.Decoder(), ChromaticAdapter{PointD65, PointD50, Bradford, false, 0}, SpaceProPhotoRGB.Encoder())
+
+    src := image.NewNRGBA64(image.Rect(3, -7, 70, 50))
+    for i := range src.Pix {
+        src.Pix[i] = uint8(i * 7919)
+    }
+
+    serial := image.NewNRGBA64(src.Rect)
+    ApplyImageContext(context.Background(), serial, src, chain, ApplyOptions{Workers: 1})
+
+    last := 0
+    parallel := image.NewNRGBA64(src.Rect)
+    err := ApplyImageContext(context.Background(), parallel, src, chain, ApplyOptions{Workers: 5, BandHeight: 3, Progress: func(done, total int) {
+        if (done <= last || done > total) {
+            t.Errorf("Progress(%v, %v) after %v", done, total, last)
+        }
+        last = done
+    }})
+
+    if (err != nil || last != 57) {
+        t.Errorf("ApplyImageContext() = %v, progress %v", err, last)
+    }
+
+    if !reflect.DeepEqual(serial.Pix, parallel.Pix) {
+        t.Errorf("parallel and serial processing differ")
+    }
+
+    // Cancellation
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+
+    if err := ApplyImageContext(ctx, parallel, src, chain, ApplyOptions{}); err != context.Canceled {
+        t.Errorf("ApplyImageContext(cancelled) = %v, want %v", err, context.Canceled)
+    }
+}
+
+func TestDither(t *testing.T) {
+    // 19596 / 65535 is 76.2495 in 8 bits, which rounds down everywhere unless dithered
+    src := image.NewGray16(image.Rect(0, 0, 64, 64))
+    for i := 0; i < len(src.Pix); i += 2 {
+        src.Pix[i], src.Pix[i + 1] = 19596 >> 8, 19596 & 0xFF
+    }
+
+    for _, test := range []struct {
+        dither Dither
+        mean float64
+    }{
+        {DitherNone, 76},
+        {DitherOrdered, 76.2495},
+        {DitherBlueNoise, 76.2495},
+        {DitherFloydSteinberg, 76.2495},
+    } {
+        dst := image.NewGray(src.Rect)
+        if err := ApplyImageContext(context.Background(), dst, src, Chain(Identity), ApplyOptions{Dither: test.dither}); err != nil {
+            t.Errorf("ApplyImageContext(Dither %v) = %v", test.dither, err)
+        }
+
+        var sum float64
+        for _, v := range dst.Pix {
+            if (v != 76 && v != 77) {
+                t.Errorf("ApplyImageContext(Dither %v) wrote %v, want 76 or 77", test.dither, v)
+                break
+            }
+            sum += float64(v)
+        }
+
+        FuzzyAssertSingle(float64(test.dither), sum / float64(len(dst.Pix)), test.mean, 0.01, "mean with Dither", t)
+    }
+
+    // The blue noise map uses every rank once
+    var seen [4096]bool
+    for _, row := range blueNoiseMap {
+        for _, v := range row {
+            if (v >= 4096 || seen[v]) {
+                t.Fatalf("blueNoiseMap has rank %v twice or out of range", v)
+            }
+            seen[v] = true
+        }
+    }
+}
@@ -0,0 +1,138 @@
+package colorplus
+
+import "math"
+
+// Interpolation between the points of a 3D lattice
+type InterpolationMode byte
+
+const (
+    Trilinear InterpolationMode = iota // weighted average of the 8 corners of the surrounding cube
+    Tetrahedral                        // weighted average of the 4 corners of the surrounding tetrahedron, preserves the neutral axis
+)
+
+// Interpolation between the entries of a 1D LUT
+type Interpolation1D byte
+
+const (
+    Linear1D Interpolation1D = iota // straight lines between entries
+    Cubic1D                         // monotone cubic Hermite spline, smooth and without overshoot between entries
+)
+
+// Interpolate on a lattice with n[i]+1 points along axis i, at the fractional indices pos. get returns the lattice entry
+// at the given integer indices
+func interpolate3D(mode InterpolationMode, get func(a, b, c int) (float64, float64, float64), n [3]int, pos [3]float64) (float64, float64, float64) {
+    var base [3]int
+    var t [3]float64
+
+    for i := range pos {
+        base[i] = cellIndex(pos[i], n[i])
+        t[i] = pos[i] - float64(base[i])
+    }
+
+    var res [3]float64
+    add := func(w float64, d [3]int) {
+        if (w == 0) {
+            return
+        }
+
+        x, y, z := get(base[0] + d[0], base[1] + d[1], base[2] + d[2])
+        res[0] += x * w
+        res[1] += y * w
+        res[2] += z * w
+    }
+
+    switch mode {
+        case Trilinear:
+            for corner := 0; corner < 8; corner++ {
+                d := [3]int{corner & 1, (corner >> 1) & 1, corner >> 2}
+                add(lerpWeight(t[0], d[0]) * lerpWeight(t[1], d[1]) * lerpWeight(t[2], d[2]), d)
+            }
+
+        case Tetrahedral:
+            // Walk from the lower to the upper corner, stepping along the axes in order of decreasing fraction
+            var axes [3]int
+            switch {
+                case t[0] >= t[1] && t[1] >= t[2]: axes = [3]int{0, 1, 2}
+                case t[0] >= t[2] && t[2] > t[1]: axes = [3]int{0, 2, 1}
+                case t[2] > t[0] && t[0] >= t[1]: axes = [3]int{2, 0, 1}
+                case t[1] > t[0] && t[0] >= t[2]: axes = [3]int{1, 0, 2}
+                case t[1] >= t[2] && t[2] > t[0]: axes = [3]int{1, 2, 0}
+                default: axes = [3]int{2, 1, 0}
+            }
+
+            var d [3]int
+            prev := 1.0
+            for _, axis := range axes {
+                add(prev - t[axis], d)
+                d[axis] = 1
+                prev = t[axis]
+            }
+            add(prev, d)
+
+        default: panic("[interpolate3D] Invalid interpolation mode!")
+    }
+
+    return res[0], res[1], res[2]
+}
+
+// The lower corner of the cell containing pos, such that the upper corner is still on the lattice
+func cellIndex(pos float64, n int) int {
+    i := int(pos)
+    if (i >= n) {
+        i = n - 1
+    }
+    if (i < 0) {
+        i = 0
+    }
+    return i
+}
+
+func lerpWeight(t float64, upper int) float64 {
+    if (upper == 1) {
+        return t
+    }
+    return 1 - t
+}
+
+// Tangents at the points (x[i], y[i]) for monotone cubic interpolation, using the Fritsch-Carlson method
+func monotoneTangents(x, y []float64) []float64 {
+    n := len(x) - 1
+    d := make([]float64, n)
+    m := make([]float64, n + 1)
+
+    for i := range d {
+        d[i] = (y[i + 1] - y[i]) / (x[i + 1] - x[i])
+    }
+
+    m[0], m[n] = d[0], d[n - 1]
+    for i := 1; i < n; i++ {
+        if (d[i - 1] * d[i] > 0) {
+            m[i] = (d[i - 1] + d[i]) / 2
+        }
+    }
+
+    // Limit the tangents where they would cause overshoot
+    for i := range d {
+        if (d[i] == 0) {
+            m[i], m[i + 1] = 0, 0
+            continue
+        }
+
+        a, b := m[i] / d[i], m[i + 1] / d[i]
+        if s := a * a + b * b; s > 9 {
+            t := 3 / math.Sqrt(s)
+            m[i], m[i + 1] = t * a * d[i], t * b * d[i]
+        }
+    }
+
+    return m
+}
+
+// Cubic Hermite interpolation between (x0, y0) and (x1, y1) with tangents m0 and m1
+func hermite(x0, x1, y0, y1, m0, m1, x float64) float64 {
+    h := x1 - x0
+    t := (x - x0) / h
+    t2, t3 := t * t, t * t * t
+
+    return (2 * t3 - 3 * t2 + 1) * y0 + (t3 - 2 * t2 + t) * h * m0 + (3 * t2 - 2 * t3) * y1 + (t3 - t2) * h * m1
+}
@@ -0,0 +1,196 @@
+package colorplus
+
+import (
+    "errors"
+    "math"
+    "sort"
+)
+
+// 1D lookup table, mapping every channel through its own sampled curve. Data holds either a single channel shared by
+// all three components of a triple, or one channel per component. Domain holds the input value of every entry, which
+// need not be evenly spaced (entries can be denser close to black, for instance) but must be strictly increasing.
+// Inputs outside the domain are clamped to it
+type LUT1D struct {
+    Title string
+    Domain []float64
+    Data [][]float64              // 1 or 3 channels, each with one entry per domain value
+    Interpolation Interpolation1D
+}
+
+var (
+    ErrInvalidLUT1D = errors.New("[LUT1D] Invalid 1D LUT")
+    ErrNonUniformDomain = errors.New("[LUT1D] The file format needs an evenly spaced domain")
+)
+
+// Create an empty LUT with size entries per channel, evenly spaced over the domain 0-1
+func MakeLUT1D(size, channels int) *LUT1D {
+    lut := &LUT1D{Domain: make([]float64, size), Data: make([][]float64, channels)}
+
+    for i := range lut.Domain {
+        lut.Domain[i] = float64(i) / float64(size - 1)
+    }
+
+    for c := range lut.Data {
+        lut.Data[c] = make([]float64, size)
+    }
+
+    return lut
+}
+
+// Precaching, bakes a filter into a single channel LUT with size entries over the domain 0-1
+func Precache1D(filter FilterSingleProvider, size int, mode Interpolation1D) *LUT1D {
+    lut := MakeLUT1D(size, 1)
+    lut.Interpolation = mode
+    lut.Assign(filter)
+
+    return lut
+}
+
+// A curve with both directions precached, itself a curve provider
+type LUT1DCurve struct {
+    Encoder, Decoder *LUT1D
+}
+
+func PrecacheCurve(curve CurveProvider, size int, mode Interpolation1D) LUT1DCurve {
+    return LUT1DCurve{Precache1D(curve.GetEncoder(), size, mode), Precache1D(curve.GetDecoder(), size, mode)}
+}
+
+func (lc LUT1DCurve) GetEncoder() FilterSingle {
+    return lc.Encoder.GetSingle()
+}
+
+func (lc LUT1DCurve) GetDecoder() FilterSingle {
+    return lc.Decoder.GetSingle()
+}
+
+// Assignment logic, samples the filter at every domain value for all channels
+func (lut *LUT1D) Assign(filter FilterSingleProvider) {
+    f := filter.GetSingle()
+
+    for c := range lut.Data {
+        for i, x := range lut.Domain {
+            lut.Data[c][i] = f(x)
+        }
+    }
+}
+
+// Samples a separate filter for each channel of a three channel LUT
+func (lut *LUT1D) AssignChannels(r, g, b FilterSingleProvider) {
+    if (len(lut.Data) != 3) {
+        panic("[LUT1D] AssignChannels needs three channels!")
+    }
+
+    for c, p := range []FilterSingleProvider{r, g, b} {
+        f := p.GetSingle()
+        for i, x := range lut.Domain {
+            lut.Data[c][i] = f(x)
+        }
+    }
+}
+
+// Filter provider implementations. GetSingle uses the first channel, GetTriple applies each channel to the matching
+// component of any color type
+func (lut *LUT1D) GetSingle() FilterSingle {
+    return lut.channel(0)
+}
+
+func (lut *LUT1D) GetTriple() FilterTriple {
+    if (len(lut.Data) == 1) {
+        return lut.channel(0).GetTriple()
+    }
+
+    return Multiplex(lut.channel(0), lut.channel(1), lut.channel(2)).GetTriple()
+}
+
+// Interpolation for a single channel
+func (lut *LUT1D) channel(c int) FilterSingle {
+    if (lut.validate() != nil) {
+        panic("[LUT1D] Invalid domain or data size!")
+    }
+
+    x, y := lut.Domain, lut.Data[c]
+    n := len(x) - 1
+    min, max, uniform := lut.uniform()
+
+    var m []float64
+    switch lut.Interpolation {
+        case Linear1D:
+        case Cubic1D: m = monotoneTangents(x, y)
+        default: panic("[LUT1D] Invalid interpolation mode!")
+    }
+
+    return func(in float64) float64 {
+        if (in <= x[0]) {
+            return y[0]
+        }
+        if (in >= x[n]) {
+            return y[n]
+        }
+
+        // Entries i and i+1 surround the input
+        var i int
+        if (uniform) {
+            i = cellIndex((in - min) / (max - min) * float64(n), n)
+        } else {
+            i = sort.SearchFloat64s(x, in) - 1
+
+            // NaN is not ordered, so the search ends past the last entry
+            if (i > n - 1) {
+                i = n - 1
+            }
+            if (i < 0) {
+                i = 0
+            }
+        }
+
+        if (m == nil) {
+            t := (in - x[i]) / (x[i + 1] - x[i])
+            return y[i] * (1 - t) + y[i + 1] * t
+        }
+
+        return hermite(x[i], x[i + 1], y[i], y[i + 1], m[i], m[i + 1], in)
+    }
+}
+
+// Consistency checks, shared by the filter and the file formats
+func (lut *LUT1D) validate() error {
+    if (len(lut.Domain) < 2 || (len(lut.Data) != 1 && len(lut.Data) != 3)) {
+        return ErrInvalidLUT1D
+    }
+
+    for i := 1; i < len(lut.Domain); i++ {
+        if !(lut.Domain[i] > lut.Domain[i - 1]) {
+            return ErrInvalidLUT1D
+        }
+    }
+
+    for _, d := range lut.Data {
+        if (len(d) != len(lut.Domain)) {
+            return ErrInvalidLUT1D
+        }
+    }
+
+    return nil
+}
+
+// Range of the domain, ok reports whether it is evenly spaced
+func (lut *LUT1D) uniform() (min, max float64, ok bool) {
+    n := len(lut.Domain) - 1
+    min, max = lut.Domain[0], lut.Domain[n]
+
+    for i, x := range lut.Domain {
+        if (math.Abs(x - (min + (max - min) * float64(i) / float64(n))) > (max - min) * 1e-9) {
+            return min, max, false
+        }
+    }
+
+    return min, max, true
+}
+
+// Entry i of channel c, shared LUTs have the same value in every channel
+func (lut *LUT1D) entry(c, i int) float64 {
+    if (len(lut.Data) == 1) {
+        c = 0
+    }
+    return lut.Data[c][i]
+}
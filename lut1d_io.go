@@ -0,0 +1,319 @@
+package colorplus
+
+import (
+    "bufio"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Serialization of LUT1D to and from 1D .cube files, .spi1d files (as used by OpenColorIO) and plain CSV. Only CSV
+// stores the domain explicitly, the other formats need it to be evenly spaced
+
+// Conversion from a 1D .cube LUT, whose domain must be the same for all channels. If all channels are equal, the
+// result has a single shared channel
+func LUT1DFromCube(cube *CubeLUT) (*LUT1D, error) {
+    min, max := cube.DomainMin, cube.DomainMax
+    if (cube.Dimensions != 1 || min.R != min.G || min.R != min.B || max.R != max.G || max.R != max.B) {
+        return nil, ErrInvalidLUT1D
+    }
+
+    channels := 1
+    for _, v := range cube.Data {
+        if (v.R != v.G || v.R != v.B) {
+            channels = 3
+            break
+        }
+    }
+
+    lut := MakeLUT1D(cube.Size, channels)
+    lut.Title = cube.Title
+
+    for i, v := range cube.Data {
+        lut.Domain[i] = min.R + (max.R - min.R) * float64(i) / float64(cube.Size - 1)
+        for c := range lut.Data {
+            lut.Data[c][i] = channelOf(v, c)
+        }
+    }
+
+    if err := lut.validate(); err != nil {
+        return nil, err
+    }
+
+    return lut, nil
+}
+
+// Conversion to a 1D .cube LUT
+func (lut *LUT1D) ToCube() (*CubeLUT, error) {
+    min, max, err := lut.uniformChecked()
+    if err != nil {
+        return nil, err
+    }
+
+    cube := MakeCubeLUT(1, len(lut.Domain))
+    cube.Title = lut.Title
+    cube.DomainMin, cube.DomainMax = RGB{min, min, min}, RGB{max, max, max}
+
+    for i := range cube.Data {
+        cube.Data[i] = RGB{lut.entry(0, i), lut.entry(1, i), lut.entry(2, i)}
+    }
+
+    return cube, nil
+}
+
+// Write the LUT in 1D .cube format to w
+func (lut *LUT1D) WriteCube(w io.Writer) (int64, error) {
+    cube, err := lut.ToCube()
+    if err != nil {
+        return 0, err
+    }
+
+    return cube.WriteTo(w)
+}
+
+// Read a LUT in 1D .cube format from r
+func ReadLUT1DCube(r io.Reader) (*LUT1D, error) {
+    cube, err := ReadCubeLUT(r)
+    if err != nil {
+        return nil, err
+    }
+
+    return LUT1DFromCube(cube)
+}
+
+// Write the LUT in .spi1d format to w
+func (lut *LUT1D) WriteSpi1D(w io.Writer) (int64, error) {
+    min, max, err := lut.uniformChecked()
+    if err != nil {
+        return 0, err
+    }
+
+    cw := &countingWriter{w: w}
+    bw := bufio.NewWriter(cw)
+
+    fmt.Fprintf(bw, "Version 1\nFrom %s %s\nLength %d\nComponents %d\n{\n", formatFloat(min), formatFloat(max), len(lut.Domain), len(lut.Data))
+
+    for i := range lut.Domain {
+        values := make([]string, len(lut.Data))
+        for c := range values {
+            values[c] = formatFloat(lut.Data[c][i])
+        }
+        fmt.Fprintf(bw, "    %s\n", strings.Join(values, " "))
+    }
+
+    fmt.Fprintf(bw, "}\n")
+
+    err = bw.Flush()
+    return cw.n, err
+}
+
+// Read a LUT in .spi1d format from r, with 1 or 3 components
+func ReadLUT1DSpi1D(r io.Reader) (*LUT1D, error) {
+    scanner := bufio.NewScanner(r)
+    line := 0
+
+    fail := func() (*LUT1D, error) {
+        return nil, fmt.Errorf("%w (line %d)", ErrInvalidLUT1D, line)
+    }
+
+    min, max := 0.0, 1.0
+    length, components := 0, 1
+    var rows [][]float64
+    inData, done := false, false
+
+    for scanner.Scan() {
+        line++
+        fields := strings.Fields(scanner.Text())
+
+        if (len(fields) == 0 || strings.HasPrefix(fields[0], "#")) {
+            continue
+        }
+
+        if (inData) {
+            if (fields[0] == "}") {
+                inData, done = false, true
+                continue
+            }
+
+            v, err := parseFloats(fields)
+            if (err != nil || len(v) != components || len(rows) == length) {
+                return fail()
+            }
+            rows = append(rows, v)
+            continue
+        }
+
+        if (done) {
+            return fail()
+        }
+
+        switch fields[0] {
+            case "Version":
+                if (len(fields) != 2 || fields[1] != "1") {
+                    return fail()
+                }
+
+            case "From":
+                v, err := parseFloats(fields[1:])
+                if (err != nil || len(v) != 2) {
+                    return fail()
+                }
+                min, max = v[0], v[1]
+
+            case "Length", "Components":
+                if (len(fields) != 2) {
+                    return fail()
+                }
+
+                n, err := strconv.Atoi(fields[1])
+                if (err != nil) {
+                    return fail()
+                }
+
+                if (fields[0] == "Length") {
+                    length = n
+                } else {
+                    components = n
+                }
+
+            case "{":
+                if (length < 2 || (components != 1 && components != 3) || len(fields) != 1) {
+                    return fail()
+                }
+                inData = true
+
+            default:
+                return fail()
+        }
+    }
+
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+
+    if (!done || len(rows) != length) {
+        return fail()
+    }
+
+    lut := MakeLUT1D(length, components)
+    for i, v := range rows {
+        lut.Domain[i] = min + (max - min) * float64(i) / float64(length - 1)
+        for c := range lut.Data {
+            lut.Data[c][i] = v[c]
+        }
+    }
+
+    if err := lut.validate(); err != nil {
+        return nil, err
+    }
+
+    return lut, nil
+}
+
+// Write the LUT as CSV to w, one line per entry with the input value followed by the value of every channel
+func (lut *LUT1D) WriteCSV(w io.Writer) (int64, error) {
+    if err := lut.validate(); err != nil {
+        return 0, err
+    }
+
+    cw := &countingWriter{w: w}
+    csvw := csv.NewWriter(cw)
+
+    for i, x := range lut.Domain {
+        record := []string{formatFloat(x)}
+        for c := range lut.Data {
+            record = append(record, formatFloat(lut.Data[c][i]))
+        }
+        csvw.Write(record)
+    }
+
+    csvw.Flush()
+    return cw.n, csvw.Error()
+}
+
+// Read a LUT as CSV from r, with 2 columns (input and shared output) or 4 (input and one output per channel). A header
+// line is skipped, and the inputs may be unevenly spaced
+func ReadLUT1DCSV(r io.Reader) (*LUT1D, error) {
+    csvr := csv.NewReader(r)
+    csvr.Comment = '#'
+    csvr.TrimLeadingSpace = true
+    csvr.FieldsPerRecord = -1 // the header may differ, rows are checked below
+
+    lut := &LUT1D{}
+
+    for n := 0; ; n++ {
+        record, err := csvr.Read()
+        if (err == io.EOF) {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+
+        line, _ := csvr.FieldPos(0)
+        v, err := parseFloats(record)
+        if err != nil {
+            if (n == 0) { // header
+                continue
+            }
+            return nil, fmt.Errorf("%w (line %d)", ErrInvalidLUT1D, line)
+        }
+
+        if (lut.Data == nil) {
+            if (len(v) != 2 && len(v) != 4) {
+                return nil, fmt.Errorf("%w (line %d)", ErrInvalidLUT1D, line)
+            }
+            lut.Data = make([][]float64, len(v) - 1)
+        }
+
+        if (len(v) != len(lut.Data) + 1) {
+            return nil, fmt.Errorf("%w: %d columns, want %d (line %d)", ErrInvalidLUT1D, len(v), len(lut.Data) + 1, line)
+        }
+
+        lut.Domain = append(lut.Domain, v[0])
+        for c := range lut.Data {
+            lut.Data[c] = append(lut.Data[c], v[c + 1])
+        }
+    }
+
+    if err := lut.validate(); err != nil {
+        return nil, err
+    }
+
+    return lut, nil
+}
+
+// Helpers
+func (lut *LUT1D) uniformChecked() (min, max float64, err error) {
+    if err := lut.validate(); err != nil {
+        return 0, 0, err
+    }
+
+    min, max, ok := lut.uniform()
+    if (!ok) {
+        return 0, 0, ErrNonUniformDomain
+    }
+
+    return min, max, nil
+}
+
+// Shortest representation which reads back exactly
+func formatFloat(v float64) string {
+    return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func parseFloats(fields []string) ([]float64, error) {
+    v := make([]float64, len(fields))
+
+    for i, f := range fields {
+        x, err := strconv.ParseFloat(f, 64)
+        if err != nil {
+            return nil, err
+        }
+        v[i] = x
+    }
+
+    return v, nil
+}
@@ -0,0 +1,138 @@
+package colorplus
+
+import (
+    "bytes"
+    "errors"
+    "math"
+    "strings"
+    "testing"
+)
+
+const testSpi1D = `Version 1
+From 0.0 2.0
+Length 3
+Components 1
+{
+    0.0
+    0.25
+    1.0
+}
+`
+
+func TestLUT1D(t *testing.T) {
+    // Precached curves stay close to the original, both directions
+    for _, mode := range []Interpolation1D{Linear1D, Cubic1D} {
+        curve := PrecacheCurve(SRGBCurve, 1024, mode)
+
+        for _, v := range []float64{0.001, 0.18, 0.5, 0.9} {
+            FuzzyAssertSingle(v, curve.GetEncoder()(v), SRGBCurve.GetEncoder()(v), 0.0005, "PrecacheCurve(SRGBCurve).GetEncoder()", t)
+            FuzzyAssertSingle(v, curve.GetDecoder()(v), SRGBCurve.GetDecoder()(v), 0.00001, "PrecacheCurve(SRGBCurve).GetDecoder()", t)
+        }
+    }
+
+    // Uneven domain, clamped outside
+    lut := &LUT1D{Domain: []float64{0, 0.1, 1}, Data: [][]float64{{0, 0.5, 1}}}
+    for _, test := range [][2]float64{{-1, 0}, {0.05, 0.25}, {0.55, 0.75}, {2, 1}} {
+        FuzzyAssertSingle(test[0], lut.GetSingle()(test[0]), test[1], allow, "LUT1D(uneven)", t)
+    }
+
+    // Cubic interpolation of a step does not overshoot
+    lut = &LUT1D{Domain: []float64{0, 1, 2, 3}, Data: [][]float64{{0, 0, 1, 1}}, Interpolation: Cubic1D}
+    prev := 0.0
+    for x := 0.0; x <= 3; x += 0.125 {
+        v := lut.GetSingle()(x)
+        if (v < prev || v > 1) {
+            t.Errorf("LUT1D(step, Cubic1D)(%v) = %v after %v", x, v, prev)
+        }
+        prev = v
+    }
+
+    // Separate channels
+    lut = MakeLUT1D(17, 3)
+    lut.AssignChannels(Invert, Identity, SRGBCurve.GetEncoder())
+
+    FuzzyAssertTriple(RGB{0.25, 0.5, 0.75}, lut.GetTriple()(RGB{0.25, 0.5, 0.75}), RGB{0.75, 0.5, SRGBCurve.GetEncoder()(0.75)}, 0.001, "LUT1D(Invert, Identity, sRGB)", t)
+}
+
+func TestLUT1DIO(t *testing.T) {
+    lut, err := ReadLUT1DSpi1D(strings.NewReader(testSpi1D))
+    if err != nil {
+        t.Fatalf("ReadLUT1DSpi1D(testSpi1D) = %v", err)
+    }
+    FuzzyAssertSingle(1.5, lut.GetSingle()(1.5), 0.625, allow, "testSpi1D", t)
+
+    // Round trips through every format, for shared and separate channels
+    shared := MakeLUT1D(33, 1)
+    shared.Assign(PQCurve{}.GetEncoder())
+
+    separate := MakeLUT1D(33, 3)
+    separate.AssignChannels(Invert, PQCurve{}.GetDecoder(), SRGBCurve.GetEncoder())
+
+    formats := []struct {
+        name string
+        write func(*LUT1D, *bytes.Buffer) (int64, error)
+        read func(*bytes.Buffer) (*LUT1D, error)
+    }{
+        {"cube", func(l *LUT1D, b *bytes.Buffer) (int64, error) { return l.WriteCube(b) }, func(b *bytes.Buffer) (*LUT1D, error) { return ReadLUT1DCube(b) }},
+        {"spi1d", func(l *LUT1D, b *bytes.Buffer) (int64, error) { return l.WriteSpi1D(b) }, func(b *bytes.Buffer) (*LUT1D, error) { return ReadLUT1DSpi1D(b) }},
+        {"csv", func(l *LUT1D, b *bytes.Buffer) (int64, error) { return l.WriteCSV(b) }, func(b *bytes.Buffer) (*LUT1D, error) { return ReadLUT1DCSV(b) }},
+    }
+
+    for _, f := range formats {
+        for _, lut := range []*LUT1D{shared, separate} {
+            var buf bytes.Buffer
+            if n, err := f.write(lut, &buf); (err != nil || n != int64(buf.Len())) {
+                t.Fatalf("%s: write = %v, %v", f.name, n, err)
+            }
+
+            res, err := f.read(&buf)
+            if err != nil {
+                t.Fatalf("%s: read = %v", f.name, err)
+            }
+
+            if (len(res.Data) != len(lut.Data)) {
+                t.Fatalf("%s: read %v channels, want %v", f.name, len(res.Data), len(lut.Data))
+            }
+
+            for c := range lut.Data {
+                for i, v := range lut.Data[c] {
+                    FuzzyAssertSingle(i, res.Data[c][i], v, 0.000001, f.name + ": round trip", t)
+                    FuzzyAssertSingle(i, res.Domain[i], lut.Domain[i], 0.000001, f.name + ": domain", t)
+                }
+            }
+        }
+    }
+
+    // Uneven domains only fit in CSV
+    uneven := &LUT1D{Domain: []float64{0, 0.1, 1}, Data: [][]float64{{0, 0.5, 1}}}
+
+    var buf bytes.Buffer
+    if _, err := uneven.WriteCube(&buf); !errors.Is(err, ErrNonUniformDomain) {
+        t.Errorf("WriteCube(uneven) = %v, want %v", err, ErrNonUniformDomain)
+    }
+
+    if res, err := ReadLUT1DCSV(strings.NewReader("in,out\n0,0\n0.1,0.5\n1,1\n")); (err != nil || res.Domain[1] != 0.1) {
+        t.Errorf("ReadLUT1DCSV(uneven) = %v, %v", res, err)
+    }
+
+    if _, err := ReadLUT1DCSV(strings.NewReader("0,0\n0.5,0.5\n0.5,1\n")); !errors.Is(err, ErrInvalidLUT1D) {
+        t.Errorf("ReadLUT1DCSV(not increasing) = %v, want %v", err, ErrInvalidLUT1D)
+    }
+
+    // Headers may have any width, rows must match the first one
+    if res, err := ReadLUT1DCSV(strings.NewReader("LUT\n0,0,0,0\n1,1,1,1\n")); (err != nil || len(res.Data) != 3) {
+        t.Errorf("ReadLUT1DCSV(short header) = %v, %v", res, err)
+    }
+
+    if _, err := ReadLUT1DCSV(strings.NewReader("0,0,0,0\n1,1\n")); !errors.Is(err, ErrInvalidLUT1D) {
+        t.Errorf("ReadLUT1DCSV(short row) = %v, want %v", err, ErrInvalidLUT1D)
+    }
+
+    // NaN passes through uneven domains
+    for _, mode := range []Interpolation1D{Linear1D, Cubic1D} {
+        uneven.Interpolation = mode
+        if res := uneven.GetSingle()(math.NaN()); !math.IsNaN(res) {
+            t.Errorf("LUT1D(uneven, %v)(NaN) = %v, want NaN", mode, res)
+        }
+    }
+}
@@ -0,0 +1,67 @@
+package colorplus
+
+import (
+    "context"
+    "runtime"
+    "sync"
+)
+
+// Run work for every index from 0 to n-1 on a pool of workers (GOMAXPROCS if workers <= 0), handing out indices in
+// order. Stops early and returns ctx.Err() if ctx is cancelled, or the first error returned by work; panics in work
+// are recovered as errors. done is called after every successful index, one call at a time
+func parallel(ctx context.Context, workers, n int, work func(i int) error, done func(i int)) error {
+    ctx, cancel := context.WithCancel(ctx)
+    defer cancel()
+
+    if (workers <= 0) {
+        workers = runtime.GOMAXPROCS(0)
+    }
+
+    jobs := make(chan int)
+
+    var wg sync.WaitGroup
+    var mu sync.Mutex
+    var failure error
+
+    run := func(i int) (err error) {
+        defer recoverError(&err)
+        return work(i)
+    }
+
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+
+            for i := range jobs {
+                err := run(i)
+
+                mu.Lock()
+                if (err != nil && failure == nil) {
+                    failure = err
+                    cancel()
+                }
+                if (done != nil && err == nil) {
+                    done(i)
+                }
+                mu.Unlock()
+            }
+        }()
+    }
+
+    err := ctx.Err()
+    for i := 0; i < n && err == nil; i++ {
+        select {
+            case jobs <- i:
+            case <-ctx.Done(): err = ctx.Err()
+        }
+    }
+
+    close(jobs)
+    wg.Wait()
+
+    if (failure != nil) {
+        return failure
+    }
+    return err
+}
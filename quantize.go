@@ -0,0 +1,109 @@
+package colorplus
+
+import (
+    "image"
+    "math"
+)
+
+// Quantization of normalized values to integer codes
+
+// Deterministic rounding, used for integer LUT3D outputs
+type RoundingMode byte
+
+const (
+    RoundNearest RoundingMode = iota // halves round up
+    RoundHalfEven                    // halves round to the even code, avoids a bias for values on exact halves
+    RoundTruncate                    // rounds down, biased by half a code on average
+)
+
+// Dithering, used when writing images. All modes except DitherFloydSteinberg depend only on the pixel position, so
+// they can be used with any number of workers
+type Dither byte
+
+const (
+    DitherNone Dither = iota // round to nearest
+    DitherOrdered            // 8x8 Bayer matrix
+    DitherBlueNoise          // 64x64 blue noise threshold map, less structured than DitherOrdered
+    DitherFloydSteinberg     // error diffusion, processes the image with a single worker
+)
+
+func (r RoundingMode) round(v float64) float64 {
+    switch r {
+        case RoundNearest: return math.Floor(v + 0.5)
+        case RoundHalfEven: return math.RoundToEven(v)
+        case RoundTruncate: return math.Floor(v)
+    }
+    panic("[RoundingMode] Invalid rounding mode!")
+}
+
+// Quantizes v (normalized to 0-1) to a code from 0 to max, for channel ch of the pixel at (x, y)
+type quantizer func(v, max float64, x, y, ch int) float64
+
+// Build a quantizer for pixels inside r. Floyd-Steinberg quantizers keep state, so they must be called for every
+// channel of every pixel in order, row by row
+func makeQuantizer(d Dither, r image.Rectangle) quantizer {
+    code := func(v, max float64) float64 {
+        return math.Max(0, math.Min(max, math.Floor(v + 0.5)))
+    }
+
+    switch d {
+        case DitherNone:
+            return func(v, max float64, x, y, ch int) float64 {
+                return code(v * max, max)
+            }
+
+        case DitherOrdered:
+            return func(v, max float64, x, y, ch int) float64 {
+                return code(v * max + (float64(bayer8[y & 7][x & 7]) + 0.5) / 64 - 0.5, max)
+            }
+
+        case DitherBlueNoise:
+            return func(v, max float64, x, y, ch int) float64 {
+                // Offset per channel, so that the channels are not dithered in lockstep
+                t := blueNoiseMap[(y + 17 * ch) & 63][(x + 29 * ch) & 63]
+                return code(v * max + (float64(t) + 0.5) / 4096 - 0.5, max)
+            }
+
+        case DitherFloydSteinberg:
+            w := r.Dx()
+            cur, next := make([]float64, 3 * (w + 2)), make([]float64, 3 * (w + 2))
+            row := r.Min.Y
+
+            return func(v, max float64, x, y, ch int) float64 {
+                if (y != row) {
+                    cur, next = next, cur
+                    for i := range next {
+                        next[i] = 0
+                    }
+                    row = y
+                }
+
+                // Errors are stored with a border of one pixel on either side
+                i := 3 * (x - r.Min.X + 1) + ch
+                want := v * max + cur[i]
+                res := code(want, max)
+                e := want - res
+
+                cur[i + 3] += e * 7 / 16
+                next[i - 3] += e * 3 / 16
+                next[i] += e * 5 / 16
+                next[i + 3] += e * 1 / 16
+
+                return res
+            }
+    }
+
+    panic("[Dither] Invalid dither mode!")
+}
+
+// Threshold maps
+var bayer8 = [8][8]uint8{
+    { 0, 32,  8, 40,  2, 34, 10, 42},
+    {48, 16, 56, 24, 50, 18, 58, 26},
+    {12, 44,  4, 36, 14, 46,  6, 38},
+    {60, 28, 52, 20, 62, 30, 54, 22},
+    { 3, 35, 11, 43,  1, 33,  9, 41},
+    {51, 19, 59, 27, 49, 17, 57, 25},
+    {15, 47,  7, 39, 13, 45,  5, 37},
+    {63, 31, 55, 23, 61, 29, 53, 21},
+}
@@ -1,6 +1,9 @@
 package colorplus
 
-import "math"
+import (
+    "errors"
+    "math"
+)
 
 // Returns argument unmodified
 var Identity = FilterSingle(func(in float64) float64 {
@@ -34,6 +37,11 @@ func (s Scale) GetSingle() FilterSingle {
     }
 }
 
+func (s Scale) linear() LinearFilter {
+    d := s.Upper - s.Lower
+    return LinearFilter{Matrix3x3{d, 0, 0, 0, d, 0, 0, 0, d}, Matrix1x3{s.Lower, s.Lower, s.Lower}, nil, nil}
+}
+
 // Swap channels around
 type Swap []SwapMode
 type SwapMode byte
@@ -59,19 +67,47 @@ func (s Swap) GetTriple() FilterTriple {
     }
 }
 
+func (s Swap) GetTripleChecked(in Triple) (FilterTriple, Triple, error) {
+    for _,v := range s {
+        if (v > BC) {
+            return nil, nil, errors.New("[Swap] Invalid swap mode!")
+        }
+    }
+
+    return s.GetTriple(), in, nil
+}
+
+func (s Swap) linear() LinearFilter {
+    m := Matrix3x3{1, 0, 0, 0, 1, 0, 0, 0, 1}
+
+    // Swapping channels of the output is the same as swapping rows of the matrix
+    for _,v := range s {
+        switch (v) {
+            case AB: m.M11, m.M12, m.M13, m.M21, m.M22, m.M23 = m.M21, m.M22, m.M23, m.M11, m.M12, m.M13
+            case AC: m.M11, m.M12, m.M13, m.M31, m.M32, m.M33 = m.M31, m.M32, m.M33, m.M11, m.M12, m.M13
+            case BC: m.M21, m.M22, m.M23, m.M31, m.M32, m.M33 = m.M31, m.M32, m.M33, m.M21, m.M22, m.M23
+        }
+    }
+
+    return LinearFilter{m, Matrix1x3{}, nil, nil}
+}
+
 // Grayscale (Note: Only works on RGB because XYZ has unknown white point)
 var Grayscale = FilterTriple(func(in Triple) Triple {
-    switch x := in.(type) {
+    switch in.(type) {
         case RGB:
             luma := Luminance(in)
 
             return in.Make(luma, luma, luma)
         default:
-            panic("[Grayscale] Unsupported color type!")
+            panic(unsupported("Grayscale", in))
     }
     return nil
 })
 
+// The same as a linear filter, which declares its RGB input for Chain and is fused with other linear filters
+var GrayscaleLinear = LinearFilter{Matrix3x3{0.2126, 0.7152, 0.0722, 0.2126, 0.7152, 0.0722, 0.2126, 0.7152, 0.0722}, Matrix1x3{}, RGB{}, RGB{}}
+
 // Range pullup
 type Pullup struct {
     Depth uint
@@ -96,3 +132,25 @@ func (p Pulldown) GetSingle() FilterSingle {
         return (in - bot) / lim
     }
 }
+
+// Range pullup for chroma channels, which are centered around zero and use a wider limited range (eg. 16-240)
+type ChromaPullup Pullup
+
+func (p ChromaPullup) GetSingle() FilterSingle {
+    mid, lim := calcChromaLimits(p.Depth, p.FullRange)
+
+    return func(in float64) float64 {
+        return in * lim + mid
+    }
+}
+
+// Range pulldown for chroma channels
+type ChromaPulldown Pullup
+
+func (p ChromaPulldown) GetSingle() FilterSingle {
+    mid, lim := calcChromaLimits(p.Depth, p.FullRange)
+
+    return func(in float64) float64 {
+        return (in - mid) / lim
+    }
+}
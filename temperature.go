@@ -0,0 +1,188 @@
+package colorplus
+
+import (
+    "fmt"
+    "math"
+)
+
+// Correlated color temperature. Temperatures are in kelvin, Duv is the signed distance from the Planckian locus in the
+// CIE 1960 uv diagram (positive above the locus, towards green). Locus points are returned as XYZ with Y = 1
+
+// CIE 1931 2° color matching functions, 380-780 nm in steps of 10 nm (coarse, but within a few kelvin of the 1 nm data)
+var cmf1931 = [...][3]float64{
+    {0.001368, 0.000039, 0.006450}, {0.004243, 0.000120, 0.020050}, {0.014310, 0.000396, 0.067850}, {0.043510, 0.001210, 0.207400},
+    {0.134380, 0.004000, 0.645600}, {0.283900, 0.011600, 1.385600}, {0.348280, 0.023000, 1.747060}, {0.336200, 0.038000, 1.772110},
+    {0.290800, 0.060000, 1.669200}, {0.195360, 0.090980, 1.287640}, {0.095640, 0.139020, 0.812950}, {0.032010, 0.208020, 0.465180},
+    {0.004900, 0.323000, 0.272000}, {0.009300, 0.503000, 0.158200}, {0.063270, 0.710000, 0.078250}, {0.165500, 0.862000, 0.042160},
+    {0.290400, 0.954000, 0.020300}, {0.433450, 0.994950, 0.008750}, {0.594500, 0.995000, 0.003900}, {0.762100, 0.952000, 0.002100},
+    {0.916300, 0.870000, 0.001650}, {1.026300, 0.757000, 0.001100}, {1.062200, 0.631000, 0.000800}, {1.002600, 0.503000, 0.000340},
+    {0.854450, 0.381000, 0.000190}, {0.642400, 0.265000, 0.000050}, {0.447900, 0.175000, 0.000020}, {0.283500, 0.107000, 0},
+    {0.164900, 0.061000, 0}, {0.087400, 0.032000, 0}, {0.046770, 0.017000, 0}, {0.022700, 0.008210, 0},
+    {0.011359, 0.004102, 0}, {0.005790, 0.002091, 0}, {0.002899, 0.001047, 0}, {0.001440, 0.000520, 0},
+    {0.000690, 0.000249, 0}, {0.000332, 0.000120, 0}, {0.000166, 0.000060, 0}, {0.000083, 0.000030, 0},
+    {0.000042, 0.000015, 0},
+}
+
+const (
+    planckC2 = 1.4388e-2 // second radiation constant (m·K)
+    planckMin = 1000.0
+    planckMax = 100000.0
+    maxDuv = 0.05        // beyond this, the CCT of a color is meaningless
+)
+
+type locusPoint struct {
+    T, u, v float64
+}
+
+// Planckian locus in 1% steps, covering slightly more than the supported range
+var planckTable = makeLocusTable(planckMin / 1.01, planckMax * 1.01, 1.01)
+
+// Color of a blackbody radiator at temperature T, from 1000 K to 100000 K
+func PlanckianLocus(T float64) (XYZ, error) {
+    if (T < planckMin || T > planckMax) {
+        return XYZ{}, fmt.Errorf("[PlanckianLocus] %w: %v K", ErrOutOfRange, T)
+    }
+
+    return uvToXYZ(planckUV(T)), nil
+}
+
+// Color of CIE daylight at temperature T, from 4000 K to 25000 K
+func DaylightLocus(T float64) (XYZ, error) {
+    var x float64
+
+    if (4000 <= T && T <= 7000) {
+        x = -4.6070E9 / (T*T*T) + 2.9678E6 / (T*T) + 9.911E1 / T + 0.244063
+    } else if (7000 < T && T <= 25000) {
+        x = -2.0064E9 / (T*T*T) + 1.9018E6 / (T*T) + 2.4748E2 / T + 0.237040
+    } else {
+        return XYZ{}, fmt.Errorf("[DaylightLocus] %w: %v K", ErrOutOfRange, T)
+    }
+
+    y := -3 * x * x + 2.87 * x - 0.275
+
+    return Yxy{1, x, y}.ToXYZ(), nil
+}
+
+// White point with the given CCT, offset from the Planckian locus by Duv along the isotemperature line
+func FromCCT(T, Duv float64) (XYZ, error) {
+    if (T < planckMin || T > planckMax) {
+        return XYZ{}, fmt.Errorf("[FromCCT] %w: %v K", ErrOutOfRange, T)
+    }
+
+    u, v := planckUV(T)
+
+    // The isotemperature line is perpendicular to the locus
+    du, dv := planckUV(T * 1.0001)
+    u0, v0 := planckUV(T / 1.0001)
+    du, dv = du - u0, dv - v0
+    n := math.Hypot(du, dv)
+
+    return uvToXYZ(u + Duv * dv / n, v - Duv * du / n), nil
+}
+
+// Estimate the CCT and Duv of a color, using Ohno's combined triangular/parabolic method on a cascade of tables
+func CCT(in Triple) (T, Duv float64, err error) {
+    var x XYZ
+
+    switch v := in.(type) {
+        case XYZ: x = v
+        case Yxy: x = v.ToXYZ()
+        case Yuv: x = v.ToXYZ()
+        default: return 0, 0, unsupported("CCT", in)
+    }
+
+    d := x.X + 15 * x.Y + 3 * x.Z
+    if (d == 0 || math.IsNaN(d)) {
+        return 0, 0, fmt.Errorf("[CCT] %w: %v", ErrOutOfRange, in)
+    }
+    u, v := 4 * x.X / d, 6 * x.Y / d
+
+    m := nearestLocusPoint(planckTable, u, v)
+    if (m == 0 || m == len(planckTable) - 1) {
+        return 0, 0, fmt.Errorf("[CCT] %w: %v", ErrOutOfRange, in)
+    }
+
+    // Refine around the coarse minimum
+    fine := makeLocusTable(planckTable[m - 1].T, planckTable[m + 1].T, math.Pow(planckTable[m + 1].T / planckTable[m - 1].T, 0.1))
+    k := nearestLocusPoint(fine, u, v)
+    k = int(math.Max(1, math.Min(float64(len(fine) - 2), float64(k))))
+
+    T, Duv = ohnoCCT(fine[k - 1], fine[k], fine[k + 1], u, v)
+
+    if (T < planckMin || T > planckMax || math.Abs(Duv) > maxDuv) {
+        return T, Duv, fmt.Errorf("[CCT] %w: %v", ErrOutOfRange, in)
+    }
+
+    return T, Duv, nil
+}
+
+// Interpolation between three neighbouring locus points, triangular close to the locus and parabolic further away
+func ohnoCCT(p0, p1, p2 locusPoint, u, v float64) (T, Duv float64) {
+    d0 := math.Hypot(u - p0.u, v - p0.v)
+    d1 := math.Hypot(u - p1.u, v - p1.v)
+    d2 := math.Hypot(u - p2.u, v - p2.v)
+
+    l := math.Hypot(p2.u - p0.u, p2.v - p0.v)
+    x := (d0 * d0 - d2 * d2 + l * l) / (2 * l)
+    T = p0.T + (p2.T - p0.T) * x / l
+
+    vx := p0.v + (p2.v - p0.v) * x / l
+    Duv = math.Copysign(math.Sqrt(math.Max(0, d0 * d0 - x * x)), v - vx)
+
+    if (math.Abs(Duv) < 0.002) {
+        return T, Duv
+    }
+
+    X := (p2.T - p1.T) * (p0.T - p2.T) * (p1.T - p0.T)
+    a := (p0.T * (d2 - d1) + p1.T * (d0 - d2) + p2.T * (d1 - d0)) / X
+    b := -(p0.T * p0.T * (d2 - d1) + p1.T * p1.T * (d0 - d2) + p2.T * p2.T * (d1 - d0)) / X
+    c := -(d0 * (p2.T - p1.T) * p1.T * p2.T + d1 * (p0.T - p2.T) * p0.T * p2.T + d2 * (p1.T - p0.T) * p0.T * p1.T) / X
+
+    T = -b / (2 * a)
+    return T, math.Copysign(a * T * T + b * T + c, Duv)
+}
+
+// Helpers
+func planckUV(T float64) (u, v float64) {
+    var X, Y, Z float64
+
+    for i, c := range cmf1931 {
+        l := float64(380 + 10 * i) * 1e-9
+        m := 1 / (l * l * l * l * l * (math.Exp(planckC2 / (l * T)) - 1))
+
+        X += m * c[0]
+        Y += m * c[1]
+        Z += m * c[2]
+    }
+
+    d := X + 15 * Y + 3 * Z
+    return 4 * X / d, 6 * Y / d
+}
+
+func uvToXYZ(u, v float64) XYZ {
+    d := 2 * u - 8 * v + 4
+    return Yxy{1, 3 * u / d, 2 * v / d}.ToXYZ()
+}
+
+func makeLocusTable(min, max, step float64) []locusPoint {
+    var res []locusPoint
+
+    for T := min; T <= max * (1 + 1e-9); T *= step {
+        u, v := planckUV(T)
+        res = append(res, locusPoint{T, u, v})
+    }
+
+    return res
+}
+
+func nearestLocusPoint(table []locusPoint, u, v float64) int {
+    best, dist := 0, math.Inf(1)
+
+    for i, p := range table {
+        if d := math.Hypot(u - p.u, v - p.v); d < dist {
+            best, dist = i, d
+        }
+    }
+
+    return best
+}
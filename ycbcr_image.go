@@ -0,0 +1,267 @@
+package colorplus
+
+import (
+    "context"
+    "fmt"
+    "image"
+    "math"
+)
+
+// Processing of image.YCbCr frames, which are decoded through a Y'CbCr matrix into R'G'B' for the filter and encoded
+// again afterwards. Subsampled chroma is upsampled to full resolution before filtering and downsampled again after
+
+// Resampling filter for chroma planes
+type ChromaFilter byte
+
+const (
+    ChromaNearest ChromaFilter = iota // nearest sample when upsampling, point sampling when downsampling
+    ChromaLinear                      // bilinear when upsampling, tent filter over the covered pixels when downsampling
+)
+
+// Position of the chroma samples relative to the luma samples they cover
+type ChromaSiting byte
+
+const (
+    ChromaCenter ChromaSiting = iota // centered in both directions (JPEG, MPEG-1)
+    ChromaLeft                       // co-sited horizontally, centered vertically (MPEG-2, H.264 default)
+    ChromaTopLeft                    // co-sited in both directions (BT.2020, BT.2100)
+)
+
+// Options for YCbCr processing, a zero Matrix means BT.601. The zero value is limited range (as used for video), frames
+// from image/jpeg are full range and need FullRange set
+type YCbCrOptions struct {
+    ApplyOptions
+    Matrix YCbCrMatrix
+    FullRange bool
+    Upsampling, Downsampling ChromaFilter
+    Siting ChromaSiting
+}
+
+// Apply a filter to every pixel of src inside the bounds of dst, writing to the same coordinates in dst (which may be
+// src itself, and may use a different subsampling ratio). The filter sees R'G'B' normalized to 0-1
+func ApplyYCbCrImage(ctx context.Context, dst, src *image.YCbCr, f FilterTripleProvider, opts YCbCrOptions) error {
+    m := opts.Matrix
+    if (m == YCbCrMatrix{}) {
+        m = YCbCrBT601
+    }
+
+    filter, out, err := getTripleChecked(Chain(m.GetDecoder(), f, Clamp{0, 1}, m.GetEncoder()), YCbCr{})
+    if err != nil {
+        return err
+    }
+
+    if _, ok := out.(YCbCr); !ok && out != nil {
+        return fmt.Errorf("[ApplyYCbCrImage] %w: filter returns %T", ErrUnsupportedType, out)
+    }
+
+    r := dst.Rect.Intersect(src.Rect)
+    w := r.Dx()
+
+    bot, lim := calcLimits(8, opts.FullRange)
+    mid, clim := calcChromaLimits(8, opts.FullRange)
+
+    // Filtered chroma at full resolution, for downsampling once all rows are done
+    cb, cr := make([]float32, w * r.Dy()), make([]float32, w * r.Dy())
+
+    srcPlane := makeChromaPlane(src, opts.Siting)
+    dstPlane := makeChromaPlane(dst, opts.Siting)
+
+    workers, band := opts.Workers, opts.BandHeight
+    if (opts.Dither == DitherFloydSteinberg) {
+        workers = 1
+    }
+    if (band <= 0) {
+        band = 16
+    }
+
+    // Luma and chroma are quantized on their own sampling grids
+    qy := makeQuantizer(opts.Dither, r)
+    qc := makeQuantizer(opts.Dither, image.Rect(dstPlane.min[0], dstPlane.min[1], dstPlane.min[0] + dstPlane.size[0], dstPlane.min[1] + dstPlane.size[1]))
+
+    // Upsampling taps are the same for every row
+    cols := make([]chromaTaps, w)
+    for x := range cols {
+        cols[x] = srcPlane.taps(opts.Upsampling, r.Min.X + x, 0)
+    }
+
+    rows := func(i int) error {
+        for y := r.Min.Y + i * band; y < r.Max.Y && y < r.Min.Y + (i + 1) * band; y++ {
+            ty := srcPlane.taps(opts.Upsampling, y, 1)
+
+            for x := r.Min.X; x < r.Max.X; x++ {
+                tx := cols[x - r.Min.X]
+
+                var b, c float64
+                for j := 0; j < 2; j++ {
+                    for k := 0; k < 2; k++ {
+                        o := srcPlane.offset(tx.pos[k], ty.pos[j])
+                        b += float64(src.Cb[o]) * tx.weight[k] * ty.weight[j]
+                        c += float64(src.Cr[o]) * tx.weight[k] * ty.weight[j]
+                    }
+                }
+
+                in := YCbCr{(float64(src.Y[src.YOffset(x, y)]) - bot) / lim, (b - mid) / clim, (c - mid) / clim}
+                res := filter(in).(YCbCr)
+
+                dst.Y[dst.YOffset(x, y)] = uint8(qy((res.Y * lim + bot) / 0xFF, 0xFF, x, y, 0))
+                pos := (y - r.Min.Y) * w + x - r.Min.X
+                cb[pos], cr[pos] = float32(res.Cb), float32(res.Cr)
+            }
+        }
+        return nil
+    }
+
+    total, done := r.Dy(), 0
+    progress := func(i int) {
+        if (opts.Progress != nil) {
+            done += int(math.Min(float64(band), float64(total - i * band)))
+            opts.Progress(done, total)
+        }
+    }
+
+    if err := parallel(ctx, workers, (total + band - 1) / band, rows, progress); err != nil {
+        return err
+    }
+
+    // Downsample into the chroma planes of dst, samples entirely outside of r are left untouched
+
+    chromaRows := func(j int) error {
+        ty, wy := dstPlane.footprint(opts.Downsampling, dstPlane.min[1] + j, 1, r.Min.Y, r.Max.Y)
+
+        for k := 0; k < dstPlane.size[0]; k++ {
+            tx, wx := dstPlane.footprint(opts.Downsampling, dstPlane.min[0] + k, 0, r.Min.X, r.Max.X)
+            if (len(tx) == 0 || len(ty) == 0) {
+                continue
+            }
+
+            var b, c float64
+            for jj := range ty {
+                for kk := range tx {
+                    pos := (ty[jj] - r.Min.Y) * w + tx[kk] - r.Min.X
+                    b += float64(cb[pos]) * wx[kk] * wy[jj]
+                    c += float64(cr[pos]) * wx[kk] * wy[jj]
+                }
+            }
+
+            cx, cy := dstPlane.min[0] + k, dstPlane.min[1] + j
+            o := dstPlane.offset(cx, cy)
+            dst.Cb[o], dst.Cr[o] = uint8(qc((b * clim + mid) / 0xFF, 0xFF, cx, cy, 1)), uint8(qc((c * clim + mid) / 0xFF, 0xFF, cx, cy, 2))
+        }
+        return nil
+    }
+
+    return parallel(ctx, workers, dstPlane.size[1], chromaRows, nil)
+}
+
+// Apply a filter to a copy of src, with the same bounds and subsampling ratio
+func FilterYCbCrImage(src *image.YCbCr, f FilterTripleProvider, opts YCbCrOptions) (*image.YCbCr, error) {
+    dst := image.NewYCbCr(src.Rect, src.SubsampleRatio)
+
+    if err := ApplyYCbCrImage(context.Background(), dst, src, f, opts); err != nil {
+        return nil, err
+    }
+
+    return dst, nil
+}
+
+// Geometry of a chroma plane. Chroma sample i (in absolute chroma coordinates) along an axis is located at the luma
+// position i * scale + siting
+type chromaPlane struct {
+    scale [2]int
+    siting [2]float64
+    min, size [2]int
+    stride int
+}
+
+func makeChromaPlane(i *image.YCbCr, siting ChromaSiting) chromaPlane {
+    var p chromaPlane
+
+    switch i.SubsampleRatio {
+        case image.YCbCrSubsampleRatio422: p.scale = [2]int{2, 1}
+        case image.YCbCrSubsampleRatio420: p.scale = [2]int{2, 2}
+        case image.YCbCrSubsampleRatio440: p.scale = [2]int{1, 2}
+        case image.YCbCrSubsampleRatio411: p.scale = [2]int{4, 1}
+        case image.YCbCrSubsampleRatio410: p.scale = [2]int{4, 2}
+        default: p.scale = [2]int{1, 1}
+    }
+
+    if (siting == ChromaCenter) {
+        p.siting[0] = float64(p.scale[0] - 1) / 2
+    }
+    if (siting != ChromaTopLeft) {
+        p.siting[1] = float64(p.scale[1] - 1) / 2
+    }
+
+    // Same layout as image.NewYCbCr
+    lo, hi := [2]int{i.Rect.Min.X, i.Rect.Min.Y}, [2]int{i.Rect.Max.X, i.Rect.Max.Y}
+    for a := 0; a < 2; a++ {
+        p.min[a] = lo[a] / p.scale[a]
+        p.size[a] = (hi[a] + p.scale[a] - 1) / p.scale[a] - p.min[a]
+    }
+    p.stride = i.CStride
+
+    return p
+}
+
+func (p chromaPlane) offset(x, y int) int {
+    return (y - p.min[1]) * p.stride + x - p.min[0]
+}
+
+// Chroma samples and weights for upsampling at luma position v along the given axis
+type chromaTaps struct {
+    pos [2]int
+    weight [2]float64
+}
+
+func (p chromaPlane) taps(mode ChromaFilter, v int, axis int) chromaTaps {
+    u := (float64(v) - p.siting[axis]) / float64(p.scale[axis])
+    lo, hi := p.min[axis], p.min[axis] + p.size[axis] - 1
+    clamp := func(i int) int {
+        return int(math.Max(float64(lo), math.Min(float64(hi), float64(i))))
+    }
+
+    if (mode == ChromaNearest) {
+        i := clamp(int(math.Floor(u + 0.5)))
+        return chromaTaps{[2]int{i, i}, [2]float64{1, 0}}
+    }
+
+    i := math.Floor(u)
+    t := u - i
+    return chromaTaps{[2]int{clamp(int(i)), clamp(int(i) + 1)}, [2]float64{1 - t, t}}
+}
+
+// Luma positions in [lo, hi) and weights for downsampling into chroma sample i along the given axis
+func (p chromaPlane) footprint(mode ChromaFilter, i int, axis, lo, hi int) ([]int, []float64) {
+    c := float64(i * p.scale[axis]) + p.siting[axis]
+    s := float64(p.scale[axis])
+
+    var pos []int
+    var weights []float64
+    var sum float64
+
+    // The sample at the chroma position, or the nearest one covered by the chroma sample at the edges of the image
+    if (mode == ChromaNearest) {
+        first, last := int(math.Max(float64(lo), float64(i * p.scale[axis]))), int(math.Min(float64(hi), float64((i + 1) * p.scale[axis]))) - 1
+        if (first > last) {
+            return nil, nil
+        }
+        return []int{int(math.Max(float64(first), math.Min(float64(last), math.Floor(c))))}, []float64{1}
+    }
+
+    // Tent filter as wide as the subsampling factor, which reduces to the sample itself without subsampling
+    for v := int(math.Ceil(c - s)); float64(v) <= c + s; v++ {
+        w := 1 - math.Abs(float64(v) - c) / s
+        if (w <= 0 || v < lo || v >= hi) {
+            continue
+        }
+
+        pos = append(pos, v)
+        weights = append(weights, w)
+        sum += w
+    }
+
+    for k := range weights {
+        weights[k] /= sum
+    }
+    return pos, weights
+}
@@ -0,0 +1,96 @@
+package colorplus
+
+import (
+    "context"
+    "image"
+    "image/color"
+    "testing"
+)
+
+func TestYCbCrImage(t *testing.T) {
+    src := image.NewYCbCr(image.Rect(1, 1, 17, 9), image.YCbCrSubsampleRatio420)
+    for i := range src.Y {
+        src.Y[i] = uint8(80 + i % 120)
+    }
+    for i := range src.Cb {
+        src.Cb[i], src.Cr[i] = 120, 136
+    }
+
+    // Identity keeps everything for constant chroma, regardless of resampling
+    for _, opts := range []YCbCrOptions{
+        {},
+        {Matrix: YCbCrBT709, Upsampling: ChromaLinear, Downsampling: ChromaLinear, Siting: ChromaLeft},
+        {Matrix: YCbCrBT2020, FullRange: true, Upsampling: ChromaLinear, Siting: ChromaTopLeft},
+    } {
+        out, err := FilterYCbCrImage(src, Chain(Identity), opts)
+        if (err != nil) {
+            t.Errorf("FilterYCbCrImage(%v) = %v.", opts, err)
+            continue
+        }
+
+        for i := range out.Y {
+            if (out.Y[i] != src.Y[i]) {
+                t.Errorf("FilterYCbCrImage(%v).Y[%d] = %v, want %v.", opts, i, out.Y[i], src.Y[i])
+                break
+            }
+        }
+        for i := range out.Cb {
+            if (out.Cb[i] != 120 || out.Cr[i] != 136) {
+                t.Errorf("FilterYCbCrImage(%v) chroma[%d] = %v, %v, want 120, 136.", opts, i, out.Cb[i], out.Cr[i])
+                break
+            }
+        }
+    }
+
+    // Linear ramps in chroma survive co-sited linear resampling
+    ramp := image.NewYCbCr(image.Rect(0, 0, 16, 2), image.YCbCrSubsampleRatio422)
+    for i := range ramp.Cb {
+        ramp.Cb[i], ramp.Cr[i] = uint8(64 + 8 * (i % 8)), 128
+    }
+    for i := range ramp.Y {
+        ramp.Y[i] = 128
+    }
+
+    out, err := FilterYCbCrImage(ramp, Chain(Identity), YCbCrOptions{Upsampling: ChromaLinear, Downsampling: ChromaLinear, Siting: ChromaTopLeft})
+    if (err != nil) {
+        t.Errorf("FilterYCbCrImage(ramp) = %v.", err)
+    } else {
+        for i := 1; i < 7; i++ {
+            if (out.Cb[i] != ramp.Cb[i]) {
+                t.Errorf("FilterYCbCrImage(ramp).Cb[%d] = %v, want %v.", i, out.Cb[i], ramp.Cb[i])
+            }
+        }
+    }
+
+    // Range handling and conversion to another subsampling ratio, in place
+    black := image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio444)
+    for i := range black.Y {
+        black.Y[i], black.Cb[i], black.Cr[i] = 16, 128, 128
+    }
+
+    dst := image.NewYCbCr(image.Rect(2, 2, 6, 6), image.YCbCrSubsampleRatio420)
+    if err := ApplyYCbCrImage(context.Background(), dst, black, Chain(Invert), YCbCrOptions{Downsampling: ChromaLinear}); err != nil {
+        t.Errorf("ApplyYCbCrImage() = %v.", err)
+    }
+
+    if (dst.Y[dst.YOffset(3, 3)] != 235 || dst.Cb[dst.COffset(3, 3)] != 128 || dst.Y[dst.YOffset(5, 5)] != 0) {
+        t.Errorf("ApplyYCbCrImage(Invert) = %v, %v, %v, want 235, 128, 0.", dst.Y[dst.YOffset(3, 3)], dst.Cb[dst.COffset(3, 3)], dst.Y[dst.YOffset(5, 5)])
+    }
+
+    // Full range BT.601 matches the conversion of image/jpeg
+    px := image.NewYCbCr(image.Rect(0, 0, 1, 1), image.YCbCrSubsampleRatio444)
+    px.Y[0], px.Cb[0], px.Cr[0] = 100, 110, 150
+
+    var seen Triple
+    capture := FilterTriple(func(in Triple) Triple {
+        seen = in
+        return in
+    })
+
+    if _, err := FilterYCbCrImage(px, capture, YCbCrOptions{FullRange: true}); err != nil {
+        t.Fatalf("FilterYCbCrImage(FullRange) = %v.", err)
+    }
+
+    r, g, b := color.YCbCrToRGB(100, 110, 150)
+    FuzzyAssertTriple([]uint8{100, 110, 150}, seen, RGB{float64(r) / 255, float64(g) / 255, float64(b) / 255}, 1.0 / 255, "FilterYCbCrImage(FullRange)", t)
+}